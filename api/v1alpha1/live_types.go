@@ -42,7 +42,8 @@ type LiveSpec struct {
 	// Git repository containing the kustomize layer that needs to be deployed
 	Repository `json:"repository,omitempty"`
 
-	// Interruptible defines if the Live can be updated while it is already actively reconciling
+	// Interruptible defines if the Live can be updated while it is already actively reconciling.
+	// The running apply of an interruptible Live is cancelled when it gets updated and the new spec is applied instead.
 	Interruptible bool `json:"interruptible,omitempty"`
 
 	// Transformers define kustomize transformer layer which will be used to transform the specified kustomize layer.
@@ -54,8 +55,58 @@ type LiveSpec struct {
 
 	// Name of the ServiceAccount to use for deploying the resources.
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Apply configures how the resources are applied to the cluster.
+	Apply *LiveApplySpec `json:"apply,omitempty"`
+
+	// ReconcileInterval enables periodic re-apply of the resources after they were successfully applied.
+	// Changes made to the applied objects outside of the Live are reverted on every re-apply.
+	// The Live stays ready while re-applying, the progress and the result are reported by the <code>Reapplying</code> condition.
+	// +optional
+	ReconcileInterval *metav1.Duration `json:"reconcileInterval,omitempty"`
+
+	// DryRun applies the resources using server-side dry-run without changing them in the cluster.
+	// Changes which would be made to each object are recorded in <code>status.plan</code>.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// LiveApplySpec configures how the resources are applied to the cluster
+type LiveApplySpec struct {
+	// ReconcileTimeout is the maximum duration of waiting for the applied resources to become ready.
+	// Waits indefinitely if not set. Ignored if waitForReady is false.
+	ReconcileTimeout *metav1.Duration `json:"reconcileTimeout,omitempty"`
+
+	// PruneTimeout is the maximum duration of waiting for the pruned resources to be deleted.
+	// Waits indefinitely if not set.
+	PruneTimeout *metav1.Duration `json:"pruneTimeout,omitempty"`
+
+	// PrunePropagationPolicy is the deletion propagation policy used when pruning resources.
+	// Defaults to <code>Background</code>.
+	// +kubebuilder:validation:Enum=Background;Foreground;Orphan
+	PrunePropagationPolicy metav1.DeletionPropagation `json:"prunePropagationPolicy,omitempty"`
+
+	// InventoryPolicy defines if resources already existing in the cluster can be taken over.
+	// Defaults to <code>AdoptIfNoInventory</code>.
+	InventoryPolicy LiveInventoryPolicy `json:"inventoryPolicy,omitempty"`
+
+	// WaitForReady defines if the apply waits for the applied resources to become ready.
+	// Defaults to true.
+	WaitForReady *bool `json:"waitForReady,omitempty"`
 }
 
+// LiveInventoryPolicy defines if resources already existing in the cluster can be taken over
+// +kubebuilder:validation:Enum=AdoptIfNoInventory;AdoptAll;MustMatch
+type LiveInventoryPolicy string
+
+const (
+	// LiveInventoryPolicyAdoptIfNoInventory takes over resources which are not managed by another Live
+	LiveInventoryPolicyAdoptIfNoInventory LiveInventoryPolicy = "AdoptIfNoInventory"
+	// LiveInventoryPolicyAdoptAll takes over all resources, including the ones managed by another Live
+	LiveInventoryPolicyAdoptAll LiveInventoryPolicy = "AdoptAll"
+	// LiveInventoryPolicyMustMatch fails to apply resources which already exist, but are not managed by the Live
+	LiveInventoryPolicyMustMatch LiveInventoryPolicy = "MustMatch"
+)
+
 // LiveStatus defines the observed state of Live
 type LiveStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -66,6 +117,118 @@ type LiveStatus struct {
 
 	// Number of consecutive apply attempts that resulted in a failure
 	Retries int `json:"retries,omitempty"`
+
+	// LastApplyTime is the time when the last apply of the resources completed
+	LastApplyTime *metav1.Time `json:"lastApplyTime,omitempty"`
+
+	// ApplyAttemptID identifies the apply attempt which is in progress or was completed last.
+	// An attempt which is still applying, but isn't tracked by the controller, was interrupted by a controller restart and is started again.
+	ApplyAttemptID string `json:"applyAttemptID,omitempty"`
+
+	// Plan contains the changes which would be made to each object by applying the resources.
+	// Populated only for Lives with <code>spec.dryRun</code> set.
+	// Failed and changed objects are listed first and the list is limited to 100 objects.
+	// +kubebuilder:validation:MaxItems=100
+	Plan []LivePlannedObject `json:"plan,omitempty"`
+
+	// Objects contains the outcome of the last apply for each of the applied and pruned objects.
+	// Failed objects are listed first and the list is limited to 100 objects.
+	// +kubebuilder:validation:MaxItems=100
+	Objects []LiveObjectStatus `json:"objects,omitempty"`
+	// ObjectCount is the number of objects in the last apply, including the ones omitted from <code>status.objects</code>
+	ObjectCount int `json:"objectCount,omitempty"`
+
+	// Hooks contains the outcome of the hooks run by the last apply, in the order of their phases.
+	// +kubebuilder:validation:MaxItems=100
+	Hooks []LiveHookStatus `json:"hooks,omitempty"`
+
+	// Waves contains the progress of applying each of the sync waves, ordered as they are applied.
+	// Populated only if the objects set the <code>kuberik.io/sync-wave</code> annotation.
+	Waves []LiveWaveStatus `json:"waves,omitempty"`
+
+	// LastAppliedCommit is the commit which was applied successfully last.
+	// It is applied again when a post-apply hook with the <code>rollback</code> failure policy fails.
+	LastAppliedCommit string `json:"lastAppliedCommit,omitempty"`
+}
+
+// MaxLiveObjectStatuses is the maximum number of objects listed in the status of the Live
+const MaxLiveObjectStatuses = 100
+
+// LiveObjectStatus is the outcome of applying, pruning and waiting for an object
+type LiveObjectStatus struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Apply is one of <code>Successful</code>, <code>Skipped</code> or <code>Failed</code>
+	Apply string `json:"apply,omitempty"`
+	// Prune is one of <code>Successful</code>, <code>Skipped</code> or <code>Failed</code>
+	Prune string `json:"prune,omitempty"`
+	// Wait is one of <code>Successful</code>, <code>Skipped</code>, <code>Timeout</code> or <code>Failed</code>
+	Wait string `json:"wait,omitempty"`
+	// Error is the error which occurred while applying or pruning the object
+	Error string `json:"error,omitempty"`
+	// Status is the kstatus of the object, one of <code>Current</code>, <code>InProgress</code>, <code>Failed</code>,
+	// <code>Terminating</code>, <code>NotFound</code> or <code>Unknown</code>
+	Status string `json:"status,omitempty"`
+	// Message describes the status of the object
+	Message string `json:"message,omitempty"`
+}
+
+// LiveWaveStatus is the progress of applying the objects of a sync wave
+type LiveWaveStatus struct {
+	Wave int `json:"wave"`
+	// Phase is one of <code>Pending</code>, <code>InProgress</code>, <code>Completed</code>, <code>Failed</code> or <code>Skipped</code>
+	Phase string `json:"phase"`
+	// Objects is the number of objects in the wave
+	Objects int `json:"objects"`
+	// Applied is the number of objects applied successfully
+	Applied int `json:"applied,omitempty"`
+	// Reconciled is the number of objects which reconciled successfully
+	Reconciled int `json:"reconciled,omitempty"`
+	// Failed is the number of objects which failed to apply or reconcile
+	Failed int `json:"failed,omitempty"`
+	// Skipped is the number of objects which weren't applied
+	Skipped int `json:"skipped,omitempty"`
+}
+
+// LiveHookStatus is the outcome of running a hook
+type LiveHookStatus struct {
+	// Phase of the hook, one of <code>pre-apply</code> or <code>post-apply</code>
+	Phase string `json:"phase"`
+	// Timeout is the maximum duration the hook was waited on to complete.
+	// It's the reconcile timeout of the Live, or 10 minutes if the Live doesn't set one.
+	Timeout          *metav1.Duration `json:"timeout,omitempty"`
+	LiveObjectStatus `json:",inline"`
+}
+
+func (o LiveObjectStatus) String() string {
+	kind := o.Kind
+	if o.Group != "" {
+		kind = kind + "." + o.Group
+	}
+	if o.Namespace == "" {
+		return fmt.Sprintf("%s %s", kind, o.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, o.Namespace, o.Name)
+}
+
+func (o LiveObjectStatus) sameObject(other LiveObjectStatus) bool {
+	return o.Group == other.Group && o.Kind == other.Kind && o.Namespace == other.Namespace && o.Name == other.Name
+}
+
+// LivePlannedObject is the change which would be made to an object by applying the resources
+type LivePlannedObject struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Action is one of <code>Create</code>, <code>Update</code>, <code>Unchanged</code>, <code>Prune</code>,
+	// <code>Skip</code> or <code>Fail</code>
+	Action string `json:"action"`
+	// Message explains why the object would be skipped or fail to apply
+	Message string `json:"message,omitempty"`
 }
 
 type LivePhaseName string
@@ -73,6 +236,8 @@ type LivePhaseName string
 type LivePhase struct {
 	Name               LivePhaseName
 	ApplyResultMessage string
+	// DryRun is set when the resources were applied using dry-run
+	DryRun bool
 }
 
 func (lp *LivePhase) applyReason() string {
@@ -80,8 +245,14 @@ func (lp *LivePhase) applyReason() string {
 	case LivePhaseApplying:
 		return ""
 	case LivePhaseSucceeded:
+		if lp.DryRun {
+			return LiveReasonDryRunSucceeded
+		}
 		return "ApplySucceeded"
 	case LivePhaseFailed:
+		if lp.DryRun {
+			return "DryRunFailed"
+		}
 		return "ApplyFailed"
 	}
 	panic(fmt.Sprintf("unsupported phase: %s", lp.Name))
@@ -93,6 +264,9 @@ const (
 	LivePhaseFailed    LivePhaseName = "Failed"
 )
 
+// LiveReasonDryRunSucceeded is the reason of the Ready condition once the dry-run of the resources succeeded
+const LiveReasonDryRunSucceeded = "DryRunSucceeded"
+
 //+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
@@ -100,6 +274,7 @@ const (
 //+kubebuilder:printcolumn:name="Commit",type="string",JSONPath=".spec.commit",description=""
 //+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
 //+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason",description=""
+//+kubebuilder:printcolumn:name="Healthy",type="string",JSONPath=".status.conditions[?(@.type==\"Healthy\")].status",description=""
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
 // Live is deploying a single Kustomize layer from a commit in a git repository.
@@ -127,8 +302,25 @@ const (
 	// LiveConditionReady is set when the Live is reconciled with the specified commit
 	LiveConditionReady       LiveConditionType = "Ready"
 	LiveConditionApplyResult LiveConditionType = "ApplyResult"
+	// LiveConditionRepositoryAuth reports which shape of credentials is used to access the git repository
+	LiveConditionRepositoryAuth LiveConditionType = "RepositoryAuth"
+	// LiveConditionHealthy is set when all the applied objects are current according to kstatus
+	LiveConditionHealthy LiveConditionType = "Healthy"
+	// LiveConditionReapplying is set while the resources of the already applied generation are re-applied
+	// after the reconcile interval, the Ready condition is kept as is meanwhile
+	LiveConditionReapplying LiveConditionType = "Reapplying"
 )
 
+// healthSeverity orders the kstatus of the objects from the healthiest to the least healthy
+var healthSeverity = map[string]int{
+	"Current":     0,
+	"Unknown":     1,
+	"InProgress":  2,
+	"Terminating": 3,
+	"NotFound":    4,
+	"Failed":      5,
+}
+
 func (l *Live) GetReadyCondition() *metav1.Condition {
 	return meta.FindStatusCondition(l.Status.Conditions, string(LiveConditionReady))
 }
@@ -177,15 +369,23 @@ func (l *Live) SetPhase(phase LivePhase) {
 		readyMessage = "applying the resources"
 	case LivePhaseSucceeded:
 		readyMessage = "apply complete"
+		if phase.DryRun {
+			readyMessage = "dry-run complete"
+		}
 	case LivePhaseFailed:
 		readyMessage = fmt.Sprintf("back-off %s failed to apply the resources", l.Backoff())
 	default:
 		panic("unknown phase")
 	}
+	readyReason := string(phase.Name)
+	if phase.Name == LivePhaseSucceeded && phase.DryRun {
+		// Nothing was applied, so the commit can't be taken as successfully deployed, e.g. to roll back to
+		readyReason = LiveReasonDryRunSucceeded
+	}
 	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
 		Type:               string(LiveConditionReady),
 		Status:             status,
-		Reason:             string(phase.Name),
+		Reason:             readyReason,
 		Message:            readyMessage,
 		ObservedGeneration: l.Generation,
 	})
@@ -201,6 +401,112 @@ func (l *Live) SetPhase(phase LivePhase) {
 	}
 }
 
+// SetApplyInterrupted records that the apply attempt was interrupted before it completed
+func (l *Live) SetApplyInterrupted(message string) {
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveConditionApplyResult),
+		Status:             metav1.ConditionFalse,
+		Reason:             "ApplyInterrupted",
+		Message:            message,
+		ObservedGeneration: l.Generation,
+	})
+}
+
+// SetRepositoryAuth records how the Live accesses the git repository
+func (l *Live) SetRepositoryAuth(authType RepositoryAuthType) {
+	var message string
+	switch authType {
+	case RepositoryAuthTypeNone:
+		message = "accessing the repository anonymously"
+	case RepositoryAuthTypeToken:
+		message = "using token from the auth secret"
+	case RepositoryAuthTypeUsernameToken:
+		message = "using username and token from the auth secret"
+	case RepositoryAuthTypeUsernamePassword:
+		message = "using username and password from the auth secret"
+	case RepositoryAuthTypeSSHKey:
+		message = "using SSH private key from the auth secret"
+	case RepositoryAuthTypeGitHubApp:
+		message = "using GitHub App installation token"
+	default:
+		message = fmt.Sprintf("using %s credentials", authType)
+	}
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveConditionRepositoryAuth),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(authType),
+		Message:            message,
+		ObservedGeneration: l.Generation,
+	})
+}
+
+// SetHealth records the kstatus of the applied objects and sets the Healthy condition.
+// Objects missing from the status are added as long as the number of listed objects stays within MaxLiveObjectStatuses.
+func (l *Live) SetHealth(health []LiveObjectStatus) {
+	var unhealthy []LiveObjectStatus
+	for _, objectHealth := range health {
+		if healthSeverity[objectHealth.Status] > 0 {
+			unhealthy = append(unhealthy, objectHealth)
+		}
+
+		found := false
+		for i := range l.Status.Objects {
+			if l.Status.Objects[i].sameObject(objectHealth) {
+				l.Status.Objects[i].Status = objectHealth.Status
+				l.Status.Objects[i].Message = objectHealth.Message
+				found = true
+				break
+			}
+		}
+		if !found && len(l.Status.Objects) < MaxLiveObjectStatuses {
+			l.Status.Objects = append(l.Status.Objects, objectHealth)
+		}
+	}
+
+	if len(unhealthy) == 0 {
+		meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+			Type:               string(LiveConditionHealthy),
+			Status:             metav1.ConditionTrue,
+			Reason:             "Current",
+			Message:            fmt.Sprintf("%d objects are current", len(health)),
+			ObservedGeneration: l.Generation,
+		})
+		return
+	}
+
+	worst := unhealthy[0]
+	for _, objectHealth := range unhealthy[1:] {
+		if healthSeverity[objectHealth.Status] > healthSeverity[worst.Status] {
+			worst = objectHealth
+		}
+	}
+	message := fmt.Sprintf("%s is %s", worst, worst.Status)
+	if worst.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, worst.Message)
+	}
+	if len(unhealthy) > 1 {
+		message = fmt.Sprintf("%s (and %d more unhealthy objects)", message, len(unhealthy)-1)
+	}
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveConditionHealthy),
+		Status:             metav1.ConditionFalse,
+		Reason:             worst.Status,
+		Message:            message,
+		ObservedGeneration: l.Generation,
+	})
+}
+
+// SetHealthUnknown sets the Healthy condition to unknown when the status of the objects can't be observed
+func (l *Live) SetHealthUnknown(err error) {
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveConditionHealthy),
+		Status:             metav1.ConditionUnknown,
+		Reason:             "WatchFailed",
+		Message:            err.Error(),
+		ObservedGeneration: l.Generation,
+	})
+}
+
 func (l *Live) NamespacedName() types.NamespacedName {
 	return types.NamespacedName{Name: l.Name, Namespace: l.Namespace}
 }
@@ -245,6 +551,56 @@ func (l *Live) backoffRemainingAt(t time.Time) time.Duration {
 	return backoffDuration
 }
 
+// SetReapplying records that the resources of the applied generation are being re-applied to revert the drift
+func (l *Live) SetReapplying() {
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveConditionReapplying),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(LivePhaseApplying),
+		Message:            "re-applying the resources",
+		ObservedGeneration: l.Generation,
+	})
+}
+
+// SetReapplied records the result of re-applying the resources of the applied generation
+func (l *Live) SetReapplied(err error) {
+	condition := metav1.Condition{
+		Type:               string(LiveConditionReapplying),
+		Status:             metav1.ConditionFalse,
+		Reason:             "ApplySucceeded",
+		Message:            "re-apply complete",
+		ObservedGeneration: l.Generation,
+	}
+	if err != nil {
+		condition.Reason = "ApplyFailed"
+		condition.Message = err.Error()
+	}
+	meta.SetStatusCondition(&l.Status.Conditions, condition)
+}
+
+// ReconcileIntervalRemaining returns the time until the resources should be re-applied.
+// Returns false if periodic re-apply isn't enabled.
+func (l *Live) ReconcileIntervalRemaining() (time.Duration, bool) {
+	return l.reconcileIntervalRemainingAt(time.Now())
+}
+
+func (l *Live) reconcileIntervalRemainingAt(t time.Time) (time.Duration, bool) {
+	if l.Spec.ReconcileInterval == nil || l.Spec.ReconcileInterval.Duration <= 0 {
+		return 0, false
+	}
+
+	var lastApply time.Time
+	if l.Status.LastApplyTime != nil {
+		lastApply = l.Status.LastApplyTime.Time
+	} else if readyCondition := l.GetReadyCondition(); readyCondition != nil {
+		lastApply = readyCondition.LastTransitionTime.Time
+	}
+	if remaining := l.Spec.ReconcileInterval.Duration - t.Sub(lastApply); remaining > 0 {
+		return remaining, true
+	}
+	return 0, true
+}
+
 //+kubebuilder:object:root=true
 
 // LiveList contains a list of Live
@@ -1,10 +1,12 @@
 package v1alpha1
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -43,3 +45,179 @@ func TestLiveBackoffRemaining(t *testing.T) {
 	currentTime = currentTime.Add(time.Millisecond * 10)
 	assert.Equal(t, live.backoffRemainingAt(currentTime), time.Millisecond*0)
 }
+
+func TestLiveSetPhaseDryRun(t *testing.T) {
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+	}
+
+	live.SetPhase(LivePhase{Name: LivePhaseApplying, DryRun: true})
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded, DryRun: true})
+	assert.Equal(t, live.GetReadyCondition().Message, "dry-run complete")
+	assert.Equal(t, live.GetReadyCondition().Reason, "DryRunSucceeded")
+	assert.Equal(t, meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionApplyResult)).Reason, "DryRunSucceeded")
+
+	live.SetPhase(LivePhase{Name: LivePhaseApplying, DryRun: true})
+	live.SetPhase(LivePhase{Name: LivePhaseFailed, DryRun: true, ApplyResultMessage: "forbidden"})
+	assert.Equal(t, meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionApplyResult)).Reason, "DryRunFailed")
+}
+
+func TestLiveSetHealth(t *testing.T) {
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+		Status: LiveStatus{
+			Objects: []LiveObjectStatus{{
+				Group:     "apps",
+				Version:   "v1",
+				Kind:      "Deployment",
+				Namespace: "default",
+				Name:      "nginx",
+				Apply:     "Successful",
+			}},
+		},
+	}
+
+	live.SetHealth([]LiveObjectStatus{{
+		Group:     "apps",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "Current",
+		Message:   "Deployment is available. Replicas: 1",
+	}, {
+		Version:   "v1",
+		Kind:      "Service",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "Current",
+	}})
+	healthy := meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionHealthy))
+	assert.Equal(t, healthy.Status, metav1.ConditionTrue)
+	assert.Equal(t, healthy.Message, "2 objects are current")
+	assert.DeepEqual(t, live.Status.Objects, []LiveObjectStatus{{
+		Group:     "apps",
+		Version:   "v1",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "nginx",
+		Apply:     "Successful",
+		Status:    "Current",
+		Message:   "Deployment is available. Replicas: 1",
+	}, {
+		Version:   "v1",
+		Kind:      "Service",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "Current",
+	}})
+
+	live.SetHealth([]LiveObjectStatus{{
+		Group:     "apps",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "InProgress",
+		Message:   "Replicas: 0/1",
+	}, {
+		Kind:      "Service",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "NotFound",
+	}})
+	healthy = meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionHealthy))
+	assert.Equal(t, healthy.Status, metav1.ConditionFalse)
+	assert.Equal(t, healthy.Reason, "NotFound")
+	assert.Equal(t, healthy.Message, "Service default/nginx is NotFound (and 1 more unhealthy objects)")
+	assert.Equal(t, live.Status.Objects[0].Status, "InProgress")
+
+	live.SetHealthUnknown(errors.New("forbidden"))
+	healthy = meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionHealthy))
+	assert.Equal(t, healthy.Status, metav1.ConditionUnknown)
+	assert.Equal(t, healthy.Reason, "WatchFailed")
+}
+
+func TestLiveReconcileIntervalRemaining(t *testing.T) {
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+	}
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded})
+	currentTime := time.Now()
+
+	_, periodic := live.reconcileIntervalRemainingAt(currentTime)
+	assert.Assert(t, !periodic)
+
+	live.Spec.ReconcileInterval = &metav1.Duration{Duration: time.Minute}
+	live.GetReadyCondition().LastTransitionTime = metav1.NewTime(currentTime)
+	remaining, periodic := live.reconcileIntervalRemainingAt(currentTime.Add(time.Second * 20))
+	assert.Assert(t, periodic)
+	assert.Equal(t, remaining, time.Second*40)
+
+	live.Status.LastApplyTime = &metav1.Time{Time: currentTime.Add(time.Second * 30)}
+	remaining, _ = live.reconcileIntervalRemainingAt(currentTime.Add(time.Second * 40))
+	assert.Equal(t, remaining, time.Second*50)
+
+	remaining, periodic = live.reconcileIntervalRemainingAt(currentTime.Add(time.Minute * 2))
+	assert.Assert(t, periodic)
+	assert.Equal(t, remaining, time.Duration(0))
+}
+
+func TestLiveSetApplyInterrupted(t *testing.T) {
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+	}
+	live.SetPhase(LivePhase{Name: LivePhaseApplying})
+	live.SetApplyInterrupted("apply attempt 1 was interrupted before completing")
+	assert.Assert(t, live.IsApplying())
+	applyResult := meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionApplyResult))
+	assert.Equal(t, applyResult.Status, metav1.ConditionFalse)
+	assert.Equal(t, applyResult.Reason, "ApplyInterrupted")
+
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded})
+	applyResult = meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionApplyResult))
+	assert.Equal(t, applyResult.Reason, "ApplySucceeded")
+}
+
+func TestLiveSetReapplying(t *testing.T) {
+	live := Live{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded})
+
+	live.SetReapplying()
+	assert.Assert(t, live.Reconciled(), "re-apply of the applied generation should keep the Live ready")
+	assert.Assert(t, !live.IsApplying())
+	assert.Assert(t, live.CanInterrupt(), "re-apply shouldn't block updates of the Live")
+	reapplying := meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionReapplying))
+	assert.Equal(t, reapplying.Status, metav1.ConditionTrue)
+
+	live.SetReapplied(errors.New("forbidden"))
+	assert.Assert(t, live.Reconciled())
+	reapplying = meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionReapplying))
+	assert.Equal(t, reapplying.Status, metav1.ConditionFalse)
+	assert.Equal(t, reapplying.Reason, "ApplyFailed")
+	assert.Equal(t, reapplying.Message, "forbidden")
+
+	live.SetReapplied(nil)
+	reapplying = meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionReapplying))
+	assert.Equal(t, reapplying.Reason, "ApplySucceeded")
+}
+
+func TestLiveSetRepositoryAuth(t *testing.T) {
+	live := Live{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	live.SetRepositoryAuth(RepositoryAuthTypeSSHKey)
+	condition := meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionRepositoryAuth))
+	assert.Equal(t, condition.Reason, string(RepositoryAuthTypeSSHKey))
+	assert.Equal(t, condition.Message, "using SSH private key from the auth secret")
+
+	// Unknown auth types are reported instead of crashing the controller
+	live.SetRepositoryAuth(RepositoryAuthType("OIDC"))
+	condition = meta.FindStatusCondition(live.Status.Conditions, string(LiveConditionRepositoryAuth))
+	assert.Equal(t, condition.Reason, "OIDC")
+	assert.Equal(t, condition.Message, "using OIDC credentials")
+}
@@ -17,10 +17,12 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -28,7 +30,11 @@ import (
 // log is for logging in this package.
 var livelog = logf.Log.WithName("live-resource")
 
+// liveWebhookClient is used to read the secrets referenced by the Lives during the validation
+var liveWebhookClient client.Reader
+
 func (r *Live) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	liveWebhookClient = mgr.GetAPIReader()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -54,8 +60,7 @@ var _ webhook.Validator = &Live{}
 func (r *Live) ValidateCreate() error {
 	livelog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
-	return nil
+	return r.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -72,7 +77,7 @@ func (r *Live) ValidateUpdate(old runtime.Object) error {
 		return fmt.Errorf("not allowed to change serviceAccountName")
 	}
 
-	return nil
+	return r.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -80,6 +85,26 @@ func (r *Live) ValidateDelete() error {
 	panic("unimplmented")
 }
 
+func (r *Live) validate() error {
+	if err := r.validateReconcileInterval(); err != nil {
+		return err
+	}
+	if err := r.Spec.Repository.validate(); err != nil {
+		return err
+	}
+	if liveWebhookClient == nil {
+		return nil
+	}
+	return r.Spec.Repository.validateAuth(context.TODO(), liveWebhookClient, r.Namespace)
+}
+
+func (r *Live) validateReconcileInterval() error {
+	if r.Spec.ReconcileInterval != nil && r.Spec.ReconcileInterval.Duration <= 0 {
+		return fmt.Errorf("reconcileInterval must be positive")
+	}
+	return nil
+}
+
 func (r *Live) CanInterrupt() bool {
 	return r.Spec.Interruptible || !r.IsApplying()
 }
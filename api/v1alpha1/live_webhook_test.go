@@ -195,4 +195,28 @@ var _ = Describe("Live webhook", func() {
 			}, timeout, interval).Should(HaveOccurred())
 		})
 	})
+
+	Context("Live reconcileInterval", func() {
+		It("Should deny a non-positive reconcileInterval", func() {
+			ctx := context.Background()
+			live := &Live{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "reconcile-interval",
+					Namespace: LiveNamespace,
+				},
+				Spec: LiveSpec{
+					Path: LivePath,
+					Repository: Repository{
+						URL: "https://github.com/kuberik/kuberik",
+					},
+					Commit:            plumbing.ZeroHash.String(),
+					ReconcileInterval: &metav1.Duration{Duration: -time.Minute},
+				},
+			}
+			Expect(k8sClient.Create(ctx, live)).ShouldNot(Succeed())
+
+			live.Spec.ReconcileInterval = &metav1.Duration{Duration: time.Minute}
+			Expect(k8sClient.Create(ctx, live)).Should(Succeed())
+		})
+	})
 })
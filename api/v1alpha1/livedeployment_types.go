@@ -17,7 +17,12 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,11 +34,61 @@ type LiveDeploymentSpec struct {
 	// Branch of the git repository specified in the Live template that will be continuously deployed.
 	Branch string `json:"branch,omitempty"`
 
+	// Tag selects the tag of the git repository which is deployed instead of the tip of the branch.
+	// The tags are polled and the branch is ignored while the tag is set.
+	Tag *TagSelector `json:"tag,omitempty"`
+
 	// Template of the created Live resource that will be used to deploy latest commit from the specified branch.
 	Template *LiveTemplate `json:"template,omitempty"`
 
 	// The duration of seconds between each fetching of the git repository.
 	PollIntervalSeconds int32 `json:"pollIntervalSeconds,omitempty"`
+
+	// Suspend stops polling the branch and updating the created Live until it is unset.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// PinnedCommit is a commit SHA that will be deployed instead of the tip of the branch.
+	// The branch is not polled while the commit is pinned.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{40}$`
+	PinnedCommit string `json:"pinnedCommit,omitempty"`
+
+	// RollbackTo is a commit from the revision history which was successfully deployed before.
+	// The commit is redeployed and the branch is not polled until the field is cleared.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{40}$`
+	RollbackTo string `json:"rollbackTo,omitempty"`
+
+	// RevisionHistoryLimit is the maximum number of revisions kept in the status. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	RevisionHistoryLimit int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// AutoRollback enables redeploying the last successfully deployed commit when the tip of the branch fails to deploy.
+	// The failed commit isn't deployed again until the branch moves.
+	AutoRollback *AutoRollbackPolicy `json:"autoRollback,omitempty"`
+}
+
+// TagSelector selects a tag of the git repository. Exactly one of the fields needs to be set.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type TagSelector struct {
+	// Name of the tag
+	Name string `json:"name,omitempty"`
+
+	// Semver is a semantic version constraint, e.g. <code>>=1.2.0 <2.0.0</code>. The tag of the highest version
+	// matching the constraint is selected. Tags which aren't semantic versions, optionally prefixed with <code>v</code>,
+	// are ignored. Pre-release versions only match constraints including a pre-release, e.g. <code>>=1.2.0-0 <2.0.0-0</code>.
+	Semver string `json:"semver,omitempty"`
+}
+
+// AutoRollbackPolicy defines when a commit is considered failed and rolled back
+type AutoRollbackPolicy struct {
+	// MaxRetries is the number of failed apply attempts of the Live after which the commit is rolled back
+	// +kubebuilder:validation:Minimum=0
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+
+	// HealthTimeoutSeconds is the duration in seconds after deploying the commit
+	// in which the Live needs to become ready, otherwise the commit is rolled back
+	// +kubebuilder:validation:Minimum=0
+	HealthTimeoutSeconds int32 `json:"healthTimeoutSeconds,omitempty"`
 }
 
 // LiveTemplate describes a Live that will be created
@@ -47,13 +102,90 @@ type LiveTemplate struct {
 
 // LiveDeploymentStatus defines the observed state of LiveDeployment
 type LiveDeploymentStatus struct {
+	// ObservedGeneration is the most recent generation of the LiveDeployment observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Commit is the commit deployed by the created Live.
+	// It is either the tip of the branch or the selected tag fetched during the last poll, or the pinned commit.
+	Commit string `json:"commit,omitempty"`
+
+	// Tag is the tag resolved during the last poll when the tag is selected instead of the branch
+	Tag string `json:"tag,omitempty"`
+
+	// Suspended is set when polling and updating of the created Live are suspended
+	Suspended bool `json:"suspended,omitempty"`
+
+	// PinnedCommit is the commit which is deployed instead of the tip of the branch
+	PinnedCommit string `json:"pinnedCommit,omitempty"`
+
+	// RollbackTo is the commit from the revision history which is redeployed instead of the tip of the branch
+	RollbackTo string `json:"rollbackTo,omitempty"`
+
+	// FailedCommit is the tip of the branch which was automatically rolled back.
+	// It isn't deployed again until the branch moves.
+	FailedCommit string `json:"failedCommit,omitempty"`
+
+	// History of the commits deployed by the created Live, starting with the most recent one
+	History []LiveDeploymentRevision `json:"history,omitempty"`
+
+	// LastPollTime is the last time the branch was successfully fetched from the git repository
+	LastPollTime *metav1.Time `json:"lastPollTime,omitempty"`
+
+	// Conditions is a list of conditions on the LiveDeployment resource.
+	// Condition <code>Ready</code> mirrors the <code>Ready</code> condition of the created Live.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// LiveDeploymentRevision is a commit deployed by the created Live
+type LiveDeploymentRevision struct {
+	// Commit deployed by the Live
+	Commit string `json:"commit"`
+	// DeployTime is the time when the Live was updated to deploy the commit
+	DeployTime metav1.Time `json:"deployTime"`
+	// Result is the reason of the <code>Ready</code> condition of the Live after deploying the commit
+	Result string `json:"result,omitempty"`
+}
+
+const (
+	// DefaultRevisionHistoryLimit is the default maximum number of revisions kept in the status
+	DefaultRevisionHistoryLimit = 10
+)
+
+// LiveDeploymentConditionType is the type of the condition
+type LiveDeploymentConditionType string
+
+const (
+	// LiveDeploymentConditionReady mirrors the Ready condition of the created Live
+	LiveDeploymentConditionReady LiveDeploymentConditionType = "Ready"
+	// LiveDeploymentConditionRolledBack is set when the tip of the branch was automatically rolled back
+	LiveDeploymentConditionRolledBack LiveDeploymentConditionType = "RolledBack"
+)
+
+const (
+	// LiveDeploymentReasonPending is used when the created Live didn't yet report status for its latest generation
+	LiveDeploymentReasonPending = "Pending"
+	// LiveDeploymentReasonRollbackRevisionNotFound is used when the commit to roll back to wasn't successfully deployed before
+	LiveDeploymentReasonRollbackRevisionNotFound = "RollbackRevisionNotFound"
+	// LiveDeploymentReasonRetriesExceeded is used when the Live failed to apply the commit too many times
+	LiveDeploymentReasonRetriesExceeded = "RetriesExceeded"
+	// LiveDeploymentReasonHealthTimeout is used when the Live didn't become ready in time after deploying the commit
+	LiveDeploymentReasonHealthTimeout = "HealthTimeout"
+	// LiveDeploymentReasonInvalidTagSelector is used when the tag selector can't select any tag
+	LiveDeploymentReasonInvalidTagSelector = "InvalidTagSelector"
+)
+
 //+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:shortName=ld
 //+kubebuilder:printcolumn:name="Branch",type="string",JSONPath=".spec.branch",description=""
+//+kubebuilder:printcolumn:name="Tag",type="string",JSONPath=".status.tag",description="",priority=1
+//+kubebuilder:printcolumn:name="Commit",type="string",JSONPath=".status.commit",description=""
+//+kubebuilder:printcolumn:name="Pinned",type="string",JSONPath=".status.pinnedCommit",description=""
+//+kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".status.suspended",description=""
+//+kubebuilder:printcolumn:name="Rollback",type="string",JSONPath=".status.rollbackTo",description="",priority=1
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason",description=""
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
 // LiveDeployment is continously deploying a single Kustomize layer from a branch
@@ -87,6 +219,202 @@ func (l *LiveDeployment) CreateLiveForCommit(commitSHA plumbing.Hash) *Live {
 	}
 }
 
+func (l *LiveDeployment) GetReadyCondition() *metav1.Condition {
+	return meta.FindStatusCondition(l.Status.Conditions, string(LiveDeploymentConditionReady))
+}
+
+// SetReadyFromLive mirrors the Ready condition of the created Live to the LiveDeployment
+func (l *LiveDeployment) SetReadyFromLive(live *Live) {
+	condition := metav1.Condition{
+		Type:               string(LiveDeploymentConditionReady),
+		Status:             metav1.ConditionUnknown,
+		Reason:             LiveDeploymentReasonPending,
+		Message:            fmt.Sprintf("waiting for Live %s to report status", live.Name),
+		ObservedGeneration: l.Generation,
+	}
+	if liveReadyCondition := live.GetReadyCondition(); liveReadyCondition != nil && liveReadyCondition.ObservedGeneration == live.Generation {
+		condition.Status = liveReadyCondition.Status
+		condition.Reason = liveReadyCondition.Reason
+		condition.Message = liveReadyCondition.Message
+	}
+	meta.SetStatusCondition(&l.Status.Conditions, condition)
+}
+
+// SetRollbackRevisionNotFound records that the commit to roll back to can't be deployed
+func (l *LiveDeployment) SetRollbackRevisionNotFound() {
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveDeploymentConditionReady),
+		Status:             metav1.ConditionFalse,
+		Reason:             LiveDeploymentReasonRollbackRevisionNotFound,
+		Message:            fmt.Sprintf("commit %s was not successfully deployed before", l.Spec.RollbackTo),
+		ObservedGeneration: l.Generation,
+	})
+}
+
+// SetInvalidTagSelector marks the LiveDeployment as not ready because of the invalid tag selector
+func (l *LiveDeployment) SetInvalidTagSelector(err error) {
+	meta.SetStatusCondition(&l.Status.Conditions, metav1.Condition{
+		Type:               string(LiveDeploymentConditionReady),
+		Status:             metav1.ConditionFalse,
+		Reason:             LiveDeploymentReasonInvalidTagSelector,
+		Message:            err.Error(),
+		ObservedGeneration: l.Generation,
+	})
+}
+
+// Validate checks that exactly one way of selecting the tag is set and that the semver constraint is valid
+func (s *TagSelector) Validate() error {
+	if (s.Name == "") == (s.Semver == "") {
+		return fmt.Errorf("exactly one of tag name and semver needs to be set")
+	}
+	if s.Semver != "" {
+		if _, err := semver.NewConstraint(s.Semver); err != nil {
+			return fmt.Errorf("invalid semver constraint %q: %v", s.Semver, err)
+		}
+	}
+	return nil
+}
+
+// RecordRevision adds the commit deployed by the Live to the revision history
+// and updates its result once the Live reports status for it
+func (l *LiveDeployment) RecordRevision(live *Live) {
+	if live.Spec.Commit == "" {
+		return
+	}
+
+	if len(l.Status.History) == 0 || l.Status.History[0].Commit != live.Spec.Commit {
+		l.Status.History = append([]LiveDeploymentRevision{{
+			Commit:     live.Spec.Commit,
+			DeployTime: metav1.Now(),
+		}}, l.Status.History...)
+	}
+	if readyCondition := live.GetReadyCondition(); readyCondition != nil && readyCondition.ObservedGeneration == live.Generation {
+		l.Status.History[0].Result = readyCondition.Reason
+	}
+
+	limit := int(l.Spec.RevisionHistoryLimit)
+	if limit <= 0 {
+		limit = DefaultRevisionHistoryLimit
+	}
+	if len(l.Status.History) > limit {
+		l.Status.History = l.Status.History[:limit]
+	}
+}
+
+// SucceededRevision returns the most recent revision in which the commit was successfully deployed
+func (l *LiveDeployment) SucceededRevision(commit string) *LiveDeploymentRevision {
+	for i, revision := range l.Status.History {
+		if revision.Commit == commit && revision.Result == string(LivePhaseSucceeded) {
+			return &l.Status.History[i]
+		}
+	}
+	return nil
+}
+
+// AutoRollbackEnabled returns true if the failed tip of the branch should be automatically rolled back
+func (l *LiveDeployment) AutoRollbackEnabled() bool {
+	return l.Spec.AutoRollback != nil && l.Spec.RollbackTo == "" && l.Spec.PinnedCommit == ""
+}
+
+// AutoRollbackReason returns the reason for rolling back the commit deployed by the Live
+// or an empty string if the commit shouldn't be rolled back
+func (l *LiveDeployment) AutoRollbackReason(live *Live, now time.Time) string {
+	policy := l.Spec.AutoRollback
+	if policy == nil || live.Spec.Commit == "" || live.Reconciled() {
+		return ""
+	}
+
+	readyCondition := live.GetReadyCondition()
+	if policy.MaxRetries > 0 && readyCondition != nil && readyCondition.ObservedGeneration == live.Generation &&
+		live.Status.Retries >= int(policy.MaxRetries) {
+		return LiveDeploymentReasonRetriesExceeded
+	}
+	if deadline := l.HealthDeadline(live); deadline != nil && !now.Before(*deadline) {
+		return LiveDeploymentReasonHealthTimeout
+	}
+	return ""
+}
+
+// PollDue returns whether the git repository needs to be polled, because the spec changed
+// or the poll interval passed since the last poll
+func (l *LiveDeployment) PollDue(now time.Time) bool {
+	return pollDue(l.Generation, l.Status.ObservedGeneration, l.Status.LastPollTime, l.Spec.PollIntervalSeconds, now)
+}
+
+// UntilNextPoll returns the duration until the git repository needs to be polled again
+func (l *LiveDeployment) UntilNextPoll(now time.Time) time.Duration {
+	return untilNextPoll(l.Status.LastPollTime, l.Spec.PollIntervalSeconds, now)
+}
+
+func pollDue(generation, observedGeneration int64, lastPollTime *metav1.Time, pollIntervalSeconds int32, now time.Time) bool {
+	return generation != observedGeneration || untilNextPoll(lastPollTime, pollIntervalSeconds, now) == 0
+}
+
+func untilNextPoll(lastPollTime *metav1.Time, pollIntervalSeconds int32, now time.Time) time.Duration {
+	if lastPollTime == nil {
+		return 0
+	}
+	if until := lastPollTime.Add(time.Duration(pollIntervalSeconds) * time.Second).Sub(now); until > 0 {
+		return until
+	}
+	return 0
+}
+
+// HealthDeadline returns the time until which the commit deployed by the Live needs to become ready
+func (l *LiveDeployment) HealthDeadline(live *Live) *time.Time {
+	policy := l.Spec.AutoRollback
+	if policy == nil || policy.HealthTimeoutSeconds <= 0 || len(l.Status.History) == 0 || l.Status.History[0].Commit != live.Spec.Commit {
+		return nil
+	}
+	if l.Status.History[0].Result == string(LivePhaseSucceeded) {
+		return nil
+	}
+	deadline := l.Status.History[0].DeployTime.Add(time.Duration(policy.HealthTimeoutSeconds) * time.Second)
+	return &deadline
+}
+
+// SetFailedCommit marks the commit as failed and returns the revision which should be deployed instead
+func (l *LiveDeployment) SetFailedCommit(commit string, reason string) *LiveDeploymentRevision {
+	l.Status.FailedCommit = commit
+	for i := range l.Status.History {
+		if l.Status.History[i].Commit == commit {
+			l.Status.History[i].Result = string(LivePhaseFailed)
+			break
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               string(LiveDeploymentConditionRolledBack),
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            fmt.Sprintf("commit %s failed, but no successfully deployed commit to roll back to", commit),
+		ObservedGeneration: l.Generation,
+	}
+	revision := l.RollbackRevision()
+	if revision != nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Message = fmt.Sprintf("commit %s failed, rolled back to %s", commit, revision.Commit)
+	}
+	meta.SetStatusCondition(&l.Status.Conditions, condition)
+	return revision
+}
+
+// ClearFailedCommit clears the failed commit once the branch moves
+func (l *LiveDeployment) ClearFailedCommit() {
+	l.Status.FailedCommit = ""
+	meta.RemoveStatusCondition(&l.Status.Conditions, string(LiveDeploymentConditionRolledBack))
+}
+
+// RollbackRevision returns the most recent successfully deployed revision which isn't the failed commit
+func (l *LiveDeployment) RollbackRevision() *LiveDeploymentRevision {
+	for i, revision := range l.Status.History {
+		if revision.Commit != l.Status.FailedCommit && revision.Result == string(LivePhaseSucceeded) {
+			return &l.Status.History[i]
+		}
+	}
+	return nil
+}
+
 //+kubebuilder:object:root=true
 
 // LiveDeploymentList contains a list of LiveDeployment
@@ -0,0 +1,224 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLiveDeploymentSetReadyFromLive(t *testing.T) {
+	liveDeployment := LiveDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 2,
+		},
+	}
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+	}
+
+	liveDeployment.SetReadyFromLive(&live)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Status, metav1.ConditionUnknown)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Reason, LiveDeploymentReasonPending)
+	assert.Equal(t, liveDeployment.GetReadyCondition().ObservedGeneration, int64(2))
+
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded})
+	liveDeployment.SetReadyFromLive(&live)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Status, metav1.ConditionTrue)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Reason, live.GetReadyCondition().Reason)
+
+	// Live spec changed, but its status wasn't yet updated
+	live.Generation += 1
+	liveDeployment.SetReadyFromLive(&live)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Status, metav1.ConditionUnknown)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Reason, LiveDeploymentReasonPending)
+}
+
+func TestLiveDeploymentRecordRevision(t *testing.T) {
+	liveDeployment := LiveDeployment{
+		Spec: LiveDeploymentSpec{
+			RevisionHistoryLimit: 2,
+		},
+	}
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+	}
+
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, len(liveDeployment.Status.History), 0)
+
+	live.Spec.Commit = "918c48b83bd081e863dbe1b80f8998f058cd8294"
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, len(liveDeployment.Status.History), 1)
+	assert.Equal(t, liveDeployment.Status.History[0].Result, "")
+	assert.Assert(t, liveDeployment.SucceededRevision(live.Spec.Commit) == nil)
+
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded})
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, len(liveDeployment.Status.History), 1)
+	assert.Equal(t, liveDeployment.Status.History[0].Result, string(LivePhaseSucceeded))
+	assert.Assert(t, liveDeployment.SucceededRevision(live.Spec.Commit) != nil)
+
+	// Status of the previous commit isn't recorded for the new commit
+	live.Spec.Commit = "af2d6a6954d532f8ffb47615169c8fdf9d383a1a"
+	live.Generation += 1
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, len(liveDeployment.Status.History), 2)
+	assert.Equal(t, liveDeployment.Status.History[0].Commit, "af2d6a6954d532f8ffb47615169c8fdf9d383a1a")
+	assert.Equal(t, liveDeployment.Status.History[0].Result, "")
+
+	live.SetPhase(LivePhase{Name: LivePhaseApplying})
+	live.SetPhase(LivePhase{Name: LivePhaseFailed})
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, liveDeployment.Status.History[0].Result, string(LivePhaseFailed))
+	assert.Assert(t, liveDeployment.SucceededRevision("af2d6a6954d532f8ffb47615169c8fdf9d383a1a") == nil)
+
+	// History is bounded by the limit
+	live.Spec.Commit = "1669dce138d9b841a518c64b10914d88f5e488ea"
+	live.Generation += 1
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, len(liveDeployment.Status.History), 2)
+	assert.Equal(t, liveDeployment.Status.History[0].Commit, "1669dce138d9b841a518c64b10914d88f5e488ea")
+	assert.Equal(t, liveDeployment.Status.History[1].Commit, "af2d6a6954d532f8ffb47615169c8fdf9d383a1a")
+	assert.Assert(t, liveDeployment.SucceededRevision("918c48b83bd081e863dbe1b80f8998f058cd8294") == nil)
+}
+
+func TestLiveDeploymentRecordRevisionDryRun(t *testing.T) {
+	liveDeployment := LiveDeployment{}
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+		Spec: LiveSpec{
+			Commit: "918c48b83bd081e863dbe1b80f8998f058cd8294",
+		},
+	}
+
+	// Nothing was applied by the dry-run, so the commit isn't a revision to roll back to
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded, DryRun: true})
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, len(liveDeployment.Status.History), 1)
+	assert.Equal(t, liveDeployment.Status.History[0].Result, LiveReasonDryRunSucceeded)
+	assert.Assert(t, liveDeployment.SucceededRevision(live.Spec.Commit) == nil)
+}
+
+func TestLiveDeploymentAutoRollback(t *testing.T) {
+	const (
+		goodCommit = "918c48b83bd081e863dbe1b80f8998f058cd8294"
+		badCommit  = "af2d6a6954d532f8ffb47615169c8fdf9d383a1a"
+	)
+	liveDeployment := LiveDeployment{
+		Spec: LiveDeploymentSpec{
+			AutoRollback: &AutoRollbackPolicy{
+				MaxRetries:           2,
+				HealthTimeoutSeconds: 60,
+			},
+		},
+	}
+	assert.Assert(t, liveDeployment.AutoRollbackEnabled())
+	live := Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation: 1,
+		},
+		Spec: LiveSpec{
+			Commit: goodCommit,
+		},
+	}
+	live.SetPhase(LivePhase{Name: LivePhaseSucceeded})
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, time.Now()), "")
+
+	// Periodic re-apply of the deployed commit isn't subject to the health timeout
+	live.SetReapplying()
+	liveDeployment.RecordRevision(&live)
+	assert.Equal(t, liveDeployment.Status.History[0].Result, string(LivePhaseSucceeded))
+	assert.Assert(t, liveDeployment.HealthDeadline(&live) == nil)
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, time.Now().Add(time.Hour)), "")
+
+	live.Spec.Commit = badCommit
+	live.Generation += 1
+	liveDeployment.RecordRevision(&live)
+	deployTime := liveDeployment.Status.History[0].DeployTime.Time
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, deployTime), "")
+
+	live.SetPhase(LivePhase{Name: LivePhaseApplying})
+	live.SetPhase(LivePhase{Name: LivePhaseFailed})
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, deployTime), "")
+	live.SetPhase(LivePhase{Name: LivePhaseApplying})
+	live.SetPhase(LivePhase{Name: LivePhaseFailed})
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, deployTime), LiveDeploymentReasonRetriesExceeded)
+
+	revision := liveDeployment.SetFailedCommit(badCommit, LiveDeploymentReasonRetriesExceeded)
+	assert.Equal(t, revision.Commit, goodCommit)
+	assert.Equal(t, liveDeployment.Status.FailedCommit, badCommit)
+	assert.Equal(t, liveDeployment.Status.History[0].Result, string(LivePhaseFailed))
+	rolledBackCondition := meta.FindStatusCondition(liveDeployment.Status.Conditions, string(LiveDeploymentConditionRolledBack))
+	assert.Equal(t, rolledBackCondition.Status, metav1.ConditionTrue)
+	assert.Equal(t, rolledBackCondition.Reason, LiveDeploymentReasonRetriesExceeded)
+
+	liveDeployment.ClearFailedCommit()
+	assert.Equal(t, liveDeployment.Status.FailedCommit, "")
+	assert.Assert(t, meta.FindStatusCondition(liveDeployment.Status.Conditions, string(LiveDeploymentConditionRolledBack)) == nil)
+
+	// Live which isn't ready after the health timeout is rolled back
+	live.Spec.Commit = "1669dce138d9b841a518c64b10914d88f5e488ea"
+	live.Generation += 1
+	live.SetPhase(LivePhase{Name: LivePhaseApplying})
+	liveDeployment.RecordRevision(&live)
+	deployTime = liveDeployment.Status.History[0].DeployTime.Time
+	assert.Equal(t, *liveDeployment.HealthDeadline(&live), deployTime.Add(time.Minute))
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, deployTime.Add(time.Second*59)), "")
+	assert.Equal(t, liveDeployment.AutoRollbackReason(&live, deployTime.Add(time.Minute)), LiveDeploymentReasonHealthTimeout)
+
+	// No rollback without a successfully deployed commit
+	liveDeployment.Status.History = liveDeployment.Status.History[:1]
+	assert.Assert(t, liveDeployment.SetFailedCommit(live.Spec.Commit, LiveDeploymentReasonHealthTimeout) == nil)
+	rolledBackCondition = meta.FindStatusCondition(liveDeployment.Status.Conditions, string(LiveDeploymentConditionRolledBack))
+	assert.Equal(t, rolledBackCondition.Status, metav1.ConditionFalse)
+
+	liveDeployment.Spec.PinnedCommit = goodCommit
+	assert.Assert(t, !liveDeployment.AutoRollbackEnabled())
+}
+
+func TestTagSelectorValidate(t *testing.T) {
+	assert.NilError(t, (&TagSelector{Name: "v1.0.0"}).Validate())
+	assert.NilError(t, (&TagSelector{Semver: ">=1.0.0 <2.0.0"}).Validate())
+	assert.ErrorContains(t, (&TagSelector{}).Validate(), "exactly one of tag name and semver needs to be set")
+	assert.ErrorContains(t, (&TagSelector{Name: "v1.0.0", Semver: ">=1.0.0"}).Validate(), "exactly one of tag name and semver needs to be set")
+	assert.ErrorContains(t, (&TagSelector{Semver: "latest"}).Validate(), `invalid semver constraint "latest"`)
+
+	liveDeployment := LiveDeployment{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+	liveDeployment.SetInvalidTagSelector((&TagSelector{}).Validate())
+	assert.Equal(t, liveDeployment.GetReadyCondition().Status, metav1.ConditionFalse)
+	assert.Equal(t, liveDeployment.GetReadyCondition().Reason, LiveDeploymentReasonInvalidTagSelector)
+	assert.Equal(t, liveDeployment.GetReadyCondition().ObservedGeneration, int64(3))
+}
+
+func TestLiveDeploymentPollDue(t *testing.T) {
+	now := time.Now()
+	liveDeployment := LiveDeployment{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       LiveDeploymentSpec{PollIntervalSeconds: 60},
+	}
+	liveDeployment.Status.ObservedGeneration = 1
+	assert.Assert(t, liveDeployment.PollDue(now), "never polled")
+	assert.Equal(t, liveDeployment.UntilNextPoll(now), time.Duration(0))
+
+	liveDeployment.Status.LastPollTime = &metav1.Time{Time: now.Add(-20 * time.Second)}
+	assert.Assert(t, !liveDeployment.PollDue(now), "polled within the interval")
+	assert.Equal(t, liveDeployment.UntilNextPoll(now), 40*time.Second)
+
+	liveDeployment.Generation = 2
+	assert.Assert(t, liveDeployment.PollDue(now), "spec changed since the last poll")
+
+	liveDeployment.Status.ObservedGeneration = 2
+	liveDeployment.Status.LastPollTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	assert.Assert(t, liveDeployment.PollDue(now), "poll interval passed")
+	assert.Equal(t, liveDeployment.UntilNextPoll(now), time.Duration(0))
+}
@@ -18,7 +18,10 @@ package v1alpha1
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
@@ -43,14 +46,154 @@ type LiveDeploymentGroupSpec struct {
 
 // LiveDeploymentGroupStatus defines the observed state of LiveDeploymentGroup
 type LiveDeploymentGroupStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the most recent generation of the LiveDeploymentGroup observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastPollTime is the last time the branches were successfully listed from the git repository
+	LastPollTime *metav1.Time `json:"lastPollTime,omitempty"`
+
+	// Branches matched in the git repository together with the summary of the LiveDeployment created for each of them
+	Branches []LiveDeploymentGroupBranchStatus `json:"branches,omitempty"`
+
+	// Number of created LiveDeployments which are ready
+	ReadyCount int32 `json:"readyCount,omitempty"`
+	// Number of created LiveDeployments which failed to apply their Live or failed to select a commit to deploy
+	FailedCount int32 `json:"failedCount,omitempty"`
+	// Number of created LiveDeployments which are still applying their Live
+	ApplyingCount int32 `json:"applyingCount,omitempty"`
+
+	// Conditions is a list of conditions on the LiveDeploymentGroup resource
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// LiveDeploymentGroupBranchStatus is a summary of the LiveDeployment created for a matched branch
+type LiveDeploymentGroupBranchStatus struct {
+	// Name of the matched branch
+	Branch string `json:"branch"`
+	// Name of the LiveDeployment deploying the branch
+	LiveDeployment string `json:"liveDeployment"`
+	// Commit deployed by the LiveDeployment
+	Commit string `json:"commit,omitempty"`
+	// Status of the <code>Ready</code> condition of the LiveDeployment
+	Ready metav1.ConditionStatus `json:"ready,omitempty"`
+	// Reason of the <code>Ready</code> condition of the LiveDeployment
+	Reason string `json:"reason,omitempty"`
 }
 
 const (
 	LiveDeploymentGroupLabel = "kuberik.io/live-deployment-group"
 )
 
+// LiveDeploymentGroupConditionType is the type of the condition
+type LiveDeploymentGroupConditionType string
+
+const (
+	// LiveDeploymentGroupConditionReady is set when the branches are listed and all of the created LiveDeployments are ready
+	LiveDeploymentGroupConditionReady LiveDeploymentGroupConditionType = "Ready"
+)
+
+const (
+	// LiveDeploymentGroupReasonListBranchesFailed is used when the branches couldn't be listed from the git repository
+	LiveDeploymentGroupReasonListBranchesFailed = "ListBranchesFailed"
+	// LiveDeploymentGroupReasonFailed is used when some of the created LiveDeployments failed
+	LiveDeploymentGroupReasonFailed = "Failed"
+	// LiveDeploymentGroupReasonApplying is used when some of the created LiveDeployments are not ready yet
+	LiveDeploymentGroupReasonApplying = "Applying"
+	// LiveDeploymentGroupReasonSucceeded is used when all of the created LiveDeployments are ready
+	LiveDeploymentGroupReasonSucceeded = "Succeeded"
+)
+
+func (ldg *LiveDeploymentGroup) GetReadyCondition() *metav1.Condition {
+	return meta.FindStatusCondition(ldg.Status.Conditions, string(LiveDeploymentGroupConditionReady))
+}
+
+// SetListBranchesFailed records that listing the branches from the git repository failed
+func (ldg *LiveDeploymentGroup) SetListBranchesFailed(err error) {
+	meta.SetStatusCondition(&ldg.Status.Conditions, metav1.Condition{
+		Type:               string(LiveDeploymentGroupConditionReady),
+		Status:             metav1.ConditionFalse,
+		Reason:             LiveDeploymentGroupReasonListBranchesFailed,
+		Message:            err.Error(),
+		ObservedGeneration: ldg.Generation,
+	})
+}
+
+// PollDue returns whether the branches need to be listed from the git repository, because the spec changed,
+// the poll interval passed since the last poll or the last poll failed
+func (ldg *LiveDeploymentGroup) PollDue(now time.Time) bool {
+	if ready := ldg.GetReadyCondition(); ready != nil && ready.Reason == LiveDeploymentGroupReasonListBranchesFailed {
+		return true
+	}
+	return pollDue(ldg.Generation, ldg.Status.ObservedGeneration, ldg.Status.LastPollTime, ldg.Spec.PollIntervalSeconds, now)
+}
+
+// UntilNextPoll returns the duration until the branches need to be listed again
+func (ldg *LiveDeploymentGroup) UntilNextPoll(now time.Time) time.Duration {
+	return untilNextPoll(ldg.Status.LastPollTime, ldg.Spec.PollIntervalSeconds, now)
+}
+
+// liveDeploymentFailedReasons are the reasons of the Ready condition of a LiveDeployment
+// which won't resolve without a change of the branch or the spec
+var liveDeploymentFailedReasons = map[string]bool{
+	string(LivePhaseFailed):                      true,
+	LiveDeploymentReasonRollbackRevisionNotFound: true,
+	LiveDeploymentReasonInvalidTagSelector:       true,
+	LiveDeploymentReasonRetriesExceeded:          true,
+	LiveDeploymentReasonHealthTimeout:            true,
+}
+
+// SetLiveDeployments records the summary of the LiveDeployments created for the matched branches
+// and sets the Ready condition based on their readiness
+func (ldg *LiveDeploymentGroup) SetLiveDeployments(liveDeployments []LiveDeployment) {
+	ldg.Status.Branches = []LiveDeploymentGroupBranchStatus{}
+	ldg.Status.ReadyCount = 0
+	ldg.Status.FailedCount = 0
+	ldg.Status.ApplyingCount = 0
+	for _, ld := range liveDeployments {
+		branchStatus := LiveDeploymentGroupBranchStatus{
+			Branch:         ld.Spec.Branch,
+			LiveDeployment: ld.Name,
+			Commit:         ld.Status.Commit,
+			Ready:          metav1.ConditionUnknown,
+		}
+		if readyCondition := ld.GetReadyCondition(); readyCondition != nil {
+			branchStatus.Ready = readyCondition.Status
+			branchStatus.Reason = readyCondition.Reason
+		}
+		ldg.Status.Branches = append(ldg.Status.Branches, branchStatus)
+
+		switch {
+		case branchStatus.Ready == metav1.ConditionTrue:
+			ldg.Status.ReadyCount++
+		case branchStatus.Ready == metav1.ConditionFalse && liveDeploymentFailedReasons[branchStatus.Reason]:
+			ldg.Status.FailedCount++
+		default:
+			ldg.Status.ApplyingCount++
+		}
+	}
+	sort.Slice(ldg.Status.Branches, func(i, j int) bool {
+		return ldg.Status.Branches[i].Branch < ldg.Status.Branches[j].Branch
+	})
+
+	condition := metav1.Condition{
+		Type:               string(LiveDeploymentGroupConditionReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             LiveDeploymentGroupReasonSucceeded,
+		Message:            fmt.Sprintf("%d of %d LiveDeployments ready", ldg.Status.ReadyCount, len(liveDeployments)),
+		ObservedGeneration: ldg.Generation,
+	}
+	switch {
+	case ldg.Status.FailedCount > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = LiveDeploymentGroupReasonFailed
+		condition.Message = fmt.Sprintf("%d of %d LiveDeployments failed", ldg.Status.FailedCount, len(liveDeployments))
+	case ldg.Status.ApplyingCount > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = LiveDeploymentGroupReasonApplying
+	}
+	meta.SetStatusCondition(&ldg.Status.Conditions, condition)
+}
+
 func (ldg *LiveDeploymentGroup) LiveDeploymentForBranch(branch string) *LiveDeployment {
 	return &LiveDeployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -82,6 +225,12 @@ func (ldg *LiveDeploymentGroup) LiveDeploymentSelector() labels.Selector {
 //+k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason",description=""
+//+kubebuilder:printcolumn:name="Ready Count",type="integer",JSONPath=".status.readyCount",description="",priority=1
+//+kubebuilder:printcolumn:name="Applying Count",type="integer",JSONPath=".status.applyingCount",description="",priority=1
+//+kubebuilder:printcolumn:name="Failed Count",type="integer",JSONPath=".status.failedCount",description="",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
 // LiveDeploymentGroup is deploying multiple Kustomize layers, each from the same path but
 // from a different branch of a git repository.
@@ -0,0 +1,94 @@
+package v1alpha1
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLiveDeploymentGroupSetLiveDeployments(t *testing.T) {
+	liveDeploymentWithReady := func(name, branch string, status metav1.ConditionStatus, reason string) LiveDeployment {
+		ld := LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       LiveDeploymentSpec{Branch: branch},
+			Status:     LiveDeploymentStatus{Commit: "e8d3ffab552895c19b9fcf7aa264d277cde33881"},
+		}
+		meta.SetStatusCondition(&ld.Status.Conditions, metav1.Condition{
+			Type:   string(LiveDeploymentConditionReady),
+			Status: status,
+			Reason: reason,
+		})
+		return ld
+	}
+
+	ldg := LiveDeploymentGroup{}
+	ldg.SetLiveDeployments([]LiveDeployment{
+		liveDeploymentWithReady("ldg-b", "b", metav1.ConditionFalse, string(LivePhaseApplying)),
+		liveDeploymentWithReady("ldg-a", "a", metav1.ConditionTrue, string(LivePhaseSucceeded)),
+		{ObjectMeta: metav1.ObjectMeta{Name: "ldg-c"}, Spec: LiveDeploymentSpec{Branch: "c"}},
+	})
+	assert.DeepEqual(t, ldg.Status.Branches, []LiveDeploymentGroupBranchStatus{
+		{Branch: "a", LiveDeployment: "ldg-a", Commit: "e8d3ffab552895c19b9fcf7aa264d277cde33881", Ready: metav1.ConditionTrue, Reason: string(LivePhaseSucceeded)},
+		{Branch: "b", LiveDeployment: "ldg-b", Commit: "e8d3ffab552895c19b9fcf7aa264d277cde33881", Ready: metav1.ConditionFalse, Reason: string(LivePhaseApplying)},
+		{Branch: "c", LiveDeployment: "ldg-c", Ready: metav1.ConditionUnknown},
+	})
+	assert.Equal(t, ldg.Status.ReadyCount, int32(1))
+	assert.Equal(t, ldg.Status.ApplyingCount, int32(2))
+	assert.Equal(t, ldg.Status.FailedCount, int32(0))
+	assert.Equal(t, ldg.GetReadyCondition().Status, metav1.ConditionFalse)
+	assert.Equal(t, ldg.GetReadyCondition().Reason, LiveDeploymentGroupReasonApplying)
+
+	ldg.SetLiveDeployments([]LiveDeployment{
+		liveDeploymentWithReady("ldg-a", "a", metav1.ConditionTrue, string(LivePhaseSucceeded)),
+		liveDeploymentWithReady("ldg-b", "b", metav1.ConditionFalse, string(LivePhaseFailed)),
+	})
+	assert.Equal(t, ldg.Status.ReadyCount, int32(1))
+	assert.Equal(t, ldg.Status.ApplyingCount, int32(0))
+	assert.Equal(t, ldg.Status.FailedCount, int32(1))
+	assert.Equal(t, ldg.GetReadyCondition().Reason, LiveDeploymentGroupReasonFailed)
+
+	ldg.SetLiveDeployments([]LiveDeployment{
+		liveDeploymentWithReady("ldg-a", "a", metav1.ConditionFalse, LiveDeploymentReasonRollbackRevisionNotFound),
+		liveDeploymentWithReady("ldg-b", "b", metav1.ConditionFalse, LiveDeploymentReasonInvalidTagSelector),
+		liveDeploymentWithReady("ldg-c", "c", metav1.ConditionFalse, LiveDeploymentReasonRetriesExceeded),
+		liveDeploymentWithReady("ldg-d", "d", metav1.ConditionFalse, LiveDeploymentReasonHealthTimeout),
+		liveDeploymentWithReady("ldg-e", "e", metav1.ConditionFalse, LiveDeploymentReasonPending),
+	})
+	assert.Equal(t, ldg.Status.ReadyCount, int32(0))
+	assert.Equal(t, ldg.Status.ApplyingCount, int32(1))
+	assert.Equal(t, ldg.Status.FailedCount, int32(4))
+	assert.Equal(t, ldg.GetReadyCondition().Reason, LiveDeploymentGroupReasonFailed)
+
+	ldg.SetLiveDeployments([]LiveDeployment{
+		liveDeploymentWithReady("ldg-a", "a", metav1.ConditionTrue, string(LivePhaseSucceeded)),
+	})
+	assert.Equal(t, ldg.GetReadyCondition().Status, metav1.ConditionTrue)
+	assert.Equal(t, ldg.GetReadyCondition().Reason, LiveDeploymentGroupReasonSucceeded)
+
+	ldg.SetListBranchesFailed(errors.New("repository not found"))
+	assert.Equal(t, ldg.GetReadyCondition().Status, metav1.ConditionFalse)
+	assert.Equal(t, ldg.GetReadyCondition().Reason, LiveDeploymentGroupReasonListBranchesFailed)
+	assert.Equal(t, ldg.GetReadyCondition().Message, "repository not found")
+}
+
+func TestLiveDeploymentGroupPollDue(t *testing.T) {
+	now := time.Now()
+	liveDeploymentGroup := LiveDeploymentGroup{
+		ObjectMeta: metav1.ObjectMeta{Generation: 1},
+		Spec:       LiveDeploymentGroupSpec{PollIntervalSeconds: 60},
+	}
+	liveDeploymentGroup.Status.ObservedGeneration = 1
+	assert.Assert(t, liveDeploymentGroup.PollDue(now), "never polled")
+
+	liveDeploymentGroup.Status.LastPollTime = &metav1.Time{Time: now.Add(-20 * time.Second)}
+	liveDeploymentGroup.SetLiveDeployments(nil)
+	assert.Assert(t, !liveDeploymentGroup.PollDue(now), "polled within the interval")
+	assert.Equal(t, liveDeploymentGroup.UntilNextPoll(now), 40*time.Second)
+
+	liveDeploymentGroup.SetListBranchesFailed(errors.New("connection refused"))
+	assert.Assert(t, liveDeploymentGroup.PollDue(now), "last poll failed")
+}
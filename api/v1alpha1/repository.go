@@ -2,11 +2,20 @@ package v1alpha1
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
 	"fmt"
+	"os"
+	"strconv"
+	"sync"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -18,23 +27,106 @@ type Repository struct {
 
 	// Authentication configuration for the git repository
 	Auth *RepositoryAuth `json:"auth,omitempty"`
+
+	// TLS configuration used when connecting to the git repository over HTTPS
+	TLSConfig *RepositoryTLSConfig `json:"tlsConfig,omitempty"`
+
+	// Submodules enables checking out the submodules of the git repository, recursively.
+	// The credentials and the TLS configuration of the repository are used for the submodules on the same host and protocol,
+	// submodules on other hosts are accessed anonymously.
+	Submodules bool `json:"submodules,omitempty"`
+
+	// LFS enables fetching the files stored with Git LFS, which are otherwise checked out as pointer files.
+	// The LFS server is read from the .lfsconfig file of the repository or derived from the repository URL.
+	// The credentials and the TLS configuration of the repository are used if the LFS server is on the same host and protocol.
+	LFS bool `json:"lfs,omitempty"`
+}
+
+// RepositoryTLSConfig defines how the certificates of a git server are verified
+type RepositoryTLSConfig struct {
+	// CABundle is a PEM encoded bundle of CA certificates which will be trusted in addition to
+	// the system certificates when verifying the certificate of the git server.
+	CABundle []byte `json:"caBundle,omitempty"`
+
+	// InsecureSkipTLSVerify disables the verification of the git server certificate.
+	// Connections to the git server are then susceptible to man-in-the-middle attacks.
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
 }
 
 // RepositoryAuth defines authentication configuration for a git repository
 type RepositoryAuth struct {
 	// SecretRef is a reference to a secret containing the credentials for a git repository.
-	// Secret needs to contain the field <code>token</code> containing a GitHub or GitLab token
-	// which has the permissions to read the repository.
+	// For HTTP(S) repositories, secret needs to contain the field <code>token</code> containing a GitHub or GitLab token
+	// which has the permissions to read the repository. Field <code>username</code> can be set to authenticate as
+	// a specific user, using either the <code>password</code> or the <code>token</code> field as the password.
+	// For SSH repositories, secret needs to contain the field <code>identity</code> containing a PEM encoded private key,
+	// optionally encrypted with the passphrase in the field <code>passphrase</code>. Field <code>known_hosts</code>
+	// can be used to pin the host keys of the git server. If it's omitted, the host keys are verified against
+	// <code>~/.ssh/known_hosts</code> of the controller pod.
+	// To authenticate as a GitHub App, secret needs to contain the fields <code>githubAppID</code>,
+	// <code>githubAppInstallationID</code> and <code>githubAppPrivateKey</code>. Short-lived installation tokens
+	// are then used to access the repository.
 	SecretRef corev1.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
 const (
-	AuthSecretTokenField = "token"
+	AuthSecretUsernameField   = "username"
+	AuthSecretPasswordField   = "password"
+	AuthSecretTokenField      = "token"
+	AuthSecretIdentityField   = "identity"
+	AuthSecretPassphraseField = "passphrase"
+	AuthSecretKnownHostsField = "known_hosts"
+
+	AuthSecretGitHubAppIDField             = "githubAppID"
+	AuthSecretGitHubAppInstallationIDField = "githubAppInstallationID"
+	AuthSecretGitHubAppPrivateKeyField     = "githubAppPrivateKey"
+)
+
+// RepositoryAuthType is the shape of the credentials used to authenticate to a git repository
+type RepositoryAuthType string
+
+const (
+	// RepositoryAuthTypeNone is used when the git repository is accessed anonymously
+	RepositoryAuthTypeNone RepositoryAuthType = "None"
+	// RepositoryAuthTypeToken is used when only a token is provided for HTTP basic auth
+	RepositoryAuthTypeToken RepositoryAuthType = "Token"
+	// RepositoryAuthTypeUsernameToken is used when a username and a token are provided for HTTP basic auth
+	RepositoryAuthTypeUsernameToken RepositoryAuthType = "UsernameToken"
+	// RepositoryAuthTypeUsernamePassword is used when a username and a password are provided for HTTP basic auth
+	RepositoryAuthTypeUsernamePassword RepositoryAuthType = "UsernamePassword"
+	// RepositoryAuthTypeSSHKey is used when a private key is provided for SSH auth
+	RepositoryAuthTypeSSHKey RepositoryAuthType = "SSHKey"
+	// RepositoryAuthTypeGitHubApp is used when GitHub App credentials are provided for minting installation tokens
+	RepositoryAuthTypeGitHubApp RepositoryAuthType = "GitHubApp"
 )
 
-func (r *Repository) GetAuthMethod(ctx context.Context, client client.Client, namespace string) (transport.AuthMethod, error) {
+const (
+	defaultSSHUser     = "git"
+	defaultHTTPUser    = "dummy"
+	gitHubAppTokenUser = "x-access-token"
+)
+
+// GitHubAppTokenProvider provides installation tokens for GitHub Apps
+// +kubebuilder:object:generate=false
+type GitHubAppTokenProvider interface {
+	InstallationToken(ctx context.Context, appID, installationID int64, privateKey []byte) (string, error)
+}
+
+// CredentialsIdentity identifies the credentials used to access the git repository in the namespace.
+// It is empty when the repository is accessed anonymously.
+func (r *Repository) CredentialsIdentity(namespace string) string {
 	if r.Auth == nil {
-		return nil, nil
+		return ""
+	}
+	return types.NamespacedName{Namespace: namespace, Name: r.Auth.SecretRef.Name}.String()
+}
+
+// GetAuthMethod reads the credentials from the referenced secret and returns the auth method
+// which can be used to access the git repository, along with the shape of the credentials used.
+// GitHub App credentials are only supported if githubApp token provider is set.
+func (r *Repository) GetAuthMethod(ctx context.Context, client client.Client, namespace string, githubApp GitHubAppTokenProvider) (transport.AuthMethod, RepositoryAuthType, error) {
+	if r.Auth == nil {
+		return nil, RepositoryAuthTypeNone, nil
 	}
 
 	authSecret := &corev1.Secret{}
@@ -43,15 +135,187 @@ func (r *Repository) GetAuthMethod(ctx context.Context, client client.Client, na
 		Namespace: namespace,
 	}, authSecret)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	endpoint, err := transport.NewEndpoint(r.URL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid repository url: %v", err)
+	}
+	if endpoint.Protocol == "ssh" {
+		auth, err := sshAuthMethod(endpoint, authSecret)
+		return auth, RepositoryAuthTypeSSHKey, err
+	}
+
+	if _, ok := authSecret.Data[AuthSecretGitHubAppIDField]; ok {
+		auth, err := gitHubAppAuthMethod(ctx, githubApp, authSecret)
+		return auth, RepositoryAuthTypeGitHubApp, err
 	}
 
-	if token, ok := authSecret.Data[AuthSecretTokenField]; ok {
+	username, hasUsername := authSecret.Data[AuthSecretUsernameField]
+	password, hasPassword := authSecret.Data[AuthSecretPasswordField]
+	token, hasToken := authSecret.Data[AuthSecretTokenField]
+	switch {
+	case hasUsername && hasPassword:
+		return &http.BasicAuth{
+			Username: string(username),
+			Password: string(password),
+		}, RepositoryAuthTypeUsernamePassword, nil
+	case hasUsername && hasToken:
+		return &http.BasicAuth{
+			Username: string(username),
+			Password: string(token),
+		}, RepositoryAuthTypeUsernameToken, nil
+	case hasToken:
 		return &http.BasicAuth{
-			Username: "dummy",
+			Username: defaultHTTPUser,
 			Password: string(token),
-		}, nil
+		}, RepositoryAuthTypeToken, nil
+	}
+
+	return nil, "", fmt.Errorf("no credentials found in secret %s/%s", namespace, r.Auth.SecretRef.Name)
+}
+
+func sshAuthMethod(endpoint *transport.Endpoint, authSecret *corev1.Secret) (transport.AuthMethod, error) {
+	identity, ok := authSecret.Data[AuthSecretIdentityField]
+	if !ok {
+		return nil, fmt.Errorf("no field %s found in secret %s/%s", AuthSecretIdentityField, authSecret.Namespace, authSecret.Name)
+	}
+
+	var signer ssh.Signer
+	var err error
+	if passphrase, ok := authSecret.Data[AuthSecretPassphraseField]; ok {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(identity, passphrase)
+	} else {
+		signer, err = ssh.ParsePrivateKey(identity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in secret %s/%s: %v", AuthSecretIdentityField, authSecret.Namespace, authSecret.Name, err)
+	}
+
+	user := endpoint.User
+	if user == "" {
+		user = defaultSSHUser
+	}
+	auth := &gitssh.PublicKeys{
+		User:   user,
+		Signer: signer,
+	}
+
+	if knownHosts, ok := authSecret.Data[AuthSecretKnownHostsField]; ok {
+		auth.HostKeyCallback, err = knownHostsCallback(knownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in secret %s/%s: %v", AuthSecretKnownHostsField, authSecret.Namespace, authSecret.Name, err)
+		}
+	}
+	return auth, nil
+}
+
+func gitHubAppAuthMethod(ctx context.Context, githubApp GitHubAppTokenProvider, authSecret *corev1.Secret) (transport.AuthMethod, error) {
+	if githubApp == nil {
+		return nil, fmt.Errorf("GitHub App authentication is not supported")
+	}
+
+	appID, err := strconv.ParseInt(string(authSecret.Data[AuthSecretGitHubAppIDField]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in secret %s/%s: %v", AuthSecretGitHubAppIDField, authSecret.Namespace, authSecret.Name, err)
+	}
+	installationID, err := strconv.ParseInt(string(authSecret.Data[AuthSecretGitHubAppInstallationIDField]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s in secret %s/%s: %v", AuthSecretGitHubAppInstallationIDField, authSecret.Namespace, authSecret.Name, err)
+	}
+	privateKey, ok := authSecret.Data[AuthSecretGitHubAppPrivateKeyField]
+	if !ok {
+		return nil, fmt.Errorf("no field %s found in secret %s/%s", AuthSecretGitHubAppPrivateKeyField, authSecret.Namespace, authSecret.Name)
+	}
+
+	token, err := githubApp.InstallationToken(ctx, appID, installationID, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub App installation token: %v", err)
+	}
+	return &http.BasicAuth{
+		Username: gitHubAppTokenUser,
+		Password: token,
+	}, nil
+}
+
+func (r *Repository) validate() error {
+	if r.TLSConfig != nil && len(r.TLSConfig.CABundle) > 0 {
+		if ok := x509.NewCertPool().AppendCertsFromPEM(r.TLSConfig.CABundle); !ok {
+			return fmt.Errorf("no valid PEM encoded certificates found in tlsConfig.caBundle")
+		}
+	}
+	return nil
+}
+
+// validateAuth checks the SSH credentials in the referenced secret, so malformed keys are surfaced
+// before the repository is accessed. The secret isn't required to exist yet, since it can be created after the object.
+func (r *Repository) validateAuth(ctx context.Context, client client.Reader, namespace string) error {
+	if r.Auth == nil {
+		return nil
+	}
+
+	endpoint, err := transport.NewEndpoint(r.URL)
+	if err != nil {
+		return fmt.Errorf("invalid repository url: %v", err)
+	}
+	if endpoint.Protocol != "ssh" {
+		return nil
+	}
+
+	authSecret := &corev1.Secret{}
+	err = client.Get(ctx, types.NamespacedName{
+		Name:      r.Auth.SecretRef.Name,
+		Namespace: namespace,
+	}, authSecret)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = sshAuthMethod(endpoint, authSecret)
+	return err
+}
+
+var knownHostsCallbacks = struct {
+	mu        sync.Mutex
+	callbacks map[[sha256.Size]byte]ssh.HostKeyCallback
+}{callbacks: map[[sha256.Size]byte]ssh.HostKeyCallback{}}
+
+// knownHostsCallback creates a host key callback which only accepts the host keys
+// listed in the provided known_hosts file contents. Callbacks are cached by the contents,
+// since the known_hosts file can only be parsed from disk.
+func knownHostsCallback(knownHosts []byte) (ssh.HostKeyCallback, error) {
+	key := sha256.Sum256(knownHosts)
+	knownHostsCallbacks.mu.Lock()
+	defer knownHostsCallbacks.mu.Unlock()
+	if callback, ok := knownHostsCallbacks.callbacks[key]; ok {
+		return callback, nil
+	}
+
+	callback, err := parseKnownHosts(knownHosts)
+	if err != nil {
+		return nil, err
+	}
+	knownHostsCallbacks.callbacks[key] = callback
+	return callback, nil
+}
+
+func parseKnownHosts(knownHosts []byte) (ssh.HostKeyCallback, error) {
+	file, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(knownHosts)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("no credentials found in secret %s/%s", namespace, r.Auth.SecretRef.Name)
+	return knownhosts.New(file.Name())
 }
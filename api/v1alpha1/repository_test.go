@@ -0,0 +1,411 @@
+package v1alpha1
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func rsaPrivateKeyPEM(t *testing.T, passphrase string) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err, "failed to generate private key")
+
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}
+	if passphrase != "" {
+		block, err = x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte(passphrase), x509.PEMCipherAES256)
+		assert.NilError(t, err, "failed to encrypt private key")
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func hostPublicKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err, "failed to generate host key")
+	hostKey, err := ssh.NewPublicKey(pub)
+	assert.NilError(t, err, "failed to convert host key")
+	return hostKey
+}
+
+func getAuthMethod(t *testing.T, url string, data map[string][]byte) (transport.AuthMethod, RepositoryAuthType, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "auth",
+			Namespace: "default",
+		},
+		Data: data,
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	repository := Repository{
+		URL: url,
+		Auth: &RepositoryAuth{
+			SecretRef: corev1.LocalObjectReference{Name: secret.Name},
+		},
+	}
+	return repository.GetAuthMethod(context.TODO(), client, secret.Namespace, nil)
+}
+
+func TestGetAuthMethodNone(t *testing.T) {
+	repository := Repository{URL: "https://github.com/kuberik/kuberik.git"}
+	auth, authType, err := repository.GetAuthMethod(context.TODO(), fake.NewClientBuilder().Build(), "default", nil)
+	assert.NilError(t, err, "failed to get auth method")
+	assert.Check(t, auth == nil)
+	assert.Equal(t, authType, RepositoryAuthTypeNone)
+}
+
+func TestGetAuthMethodHTTP(t *testing.T) {
+	testCases := []struct {
+		name         string
+		data         map[string][]byte
+		wantAuth     *http.BasicAuth
+		wantAuthType RepositoryAuthType
+		wantErr      bool
+	}{{
+		name: "token",
+		data: map[string][]byte{
+			AuthSecretTokenField: []byte("secret-token"),
+		},
+		wantAuth:     &http.BasicAuth{Username: "dummy", Password: "secret-token"},
+		wantAuthType: RepositoryAuthTypeToken,
+	}, {
+		name: "username and token",
+		data: map[string][]byte{
+			AuthSecretUsernameField: []byte("john"),
+			AuthSecretTokenField:    []byte("secret-token"),
+		},
+		wantAuth:     &http.BasicAuth{Username: "john", Password: "secret-token"},
+		wantAuthType: RepositoryAuthTypeUsernameToken,
+	}, {
+		name: "username and password",
+		data: map[string][]byte{
+			AuthSecretUsernameField: []byte("john"),
+			AuthSecretPasswordField: []byte("secret-password"),
+		},
+		wantAuth:     &http.BasicAuth{Username: "john", Password: "secret-password"},
+		wantAuthType: RepositoryAuthTypeUsernamePassword,
+	}, {
+		name: "password takes precedence over token",
+		data: map[string][]byte{
+			AuthSecretUsernameField: []byte("john"),
+			AuthSecretPasswordField: []byte("secret-password"),
+			AuthSecretTokenField:    []byte("secret-token"),
+		},
+		wantAuth:     &http.BasicAuth{Username: "john", Password: "secret-password"},
+		wantAuthType: RepositoryAuthTypeUsernamePassword,
+	}, {
+		name: "password without username",
+		data: map[string][]byte{
+			AuthSecretPasswordField: []byte("secret-password"),
+		},
+		wantErr: true,
+	}, {
+		name:    "empty secret",
+		data:    map[string][]byte{},
+		wantErr: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth, authType, err := getAuthMethod(t, "https://github.com/kuberik/kuberik.git", tc.data)
+			if tc.wantErr {
+				assert.Check(t, err != nil, "expected an error")
+				return
+			}
+			assert.NilError(t, err, "failed to get auth method")
+			assert.DeepEqual(t, auth, tc.wantAuth)
+			assert.Equal(t, authType, tc.wantAuthType)
+		})
+	}
+}
+
+func TestGetAuthMethodSSH(t *testing.T) {
+	hostKey := hostPublicKey(t)
+	knownHosts := fmt.Sprintf("[127.0.0.1]:2222 %s", ssh.MarshalAuthorizedKey(hostKey))
+	hostAddr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}
+
+	testCases := []struct {
+		name         string
+		url          string
+		data         map[string][]byte
+		wantUser     string
+		wantErr      bool
+		wantHostPins bool
+	}{{
+		name: "private key",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField: rsaPrivateKeyPEM(t, ""),
+		},
+		wantUser: "git",
+	}, {
+		name: "encrypted private key",
+		url:  "deploy@127.0.0.1:kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, "foo"),
+			AuthSecretPassphraseField: []byte("foo"),
+		},
+		wantUser: "deploy",
+	}, {
+		name: "default user",
+		url:  "ssh://127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField: rsaPrivateKeyPEM(t, ""),
+		},
+		wantUser: "git",
+	}, {
+		name: "known hosts",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, ""),
+			AuthSecretKnownHostsField: []byte(knownHosts),
+		},
+		wantUser:     "git",
+		wantHostPins: true,
+	}, {
+		name: "wrong passphrase",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, "foo"),
+			AuthSecretPassphraseField: []byte("bar"),
+		},
+		wantErr: true,
+	}, {
+		name: "missing passphrase",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField: rsaPrivateKeyPEM(t, "foo"),
+		},
+		wantErr: true,
+	}, {
+		name: "missing private key",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretTokenField: []byte("secret-token"),
+		},
+		wantErr: true,
+	}, {
+		name: "invalid private key",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField: []byte("foo"),
+		},
+		wantErr: true,
+	}, {
+		name: "invalid known hosts",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, ""),
+			AuthSecretKnownHostsField: []byte("127.0.0.1 ssh-ed25519 foo"),
+		},
+		wantErr: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth, authType, err := getAuthMethod(t, tc.url, tc.data)
+			if tc.wantErr {
+				assert.Check(t, err != nil, "expected an error")
+				return
+			}
+			assert.NilError(t, err, "failed to get auth method")
+			assert.Equal(t, authType, RepositoryAuthTypeSSHKey)
+
+			publicKeys, ok := auth.(*gitssh.PublicKeys)
+			assert.Assert(t, ok, "expected ssh public keys auth method, got %T", auth)
+			assert.Equal(t, publicKeys.User, tc.wantUser)
+
+			if tc.wantHostPins {
+				assert.NilError(t, publicKeys.HostKeyCallback("127.0.0.1:2222", hostAddr, hostKey))
+				assert.Check(t, publicKeys.HostKeyCallback("127.0.0.1:2222", hostAddr, hostPublicKey(t)) != nil, "expected unknown host key to be rejected")
+			} else {
+				assert.Check(t, publicKeys.HostKeyCallback == nil)
+			}
+		})
+	}
+}
+
+func TestRepositoryValidate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		repository Repository
+		wantErr    bool
+	}{{
+		name:       "no tls config",
+		repository: Repository{URL: "https://github.com/kuberik/kuberik.git"},
+	}, {
+		name: "insecure skip tls verify",
+		repository: Repository{
+			URL:       "https://github.com/kuberik/kuberik.git",
+			TLSConfig: &RepositoryTLSConfig{InsecureSkipTLSVerify: true},
+		},
+	}, {
+		name: "invalid ca bundle",
+		repository: Repository{
+			URL:       "https://github.com/kuberik/kuberik.git",
+			TLSConfig: &RepositoryTLSConfig{CABundle: []byte("foo")},
+		},
+		wantErr: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.repository.validate()
+			if tc.wantErr {
+				assert.Check(t, err != nil, "expected an error")
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepositoryValidateAuth(t *testing.T) {
+	testCases := []struct {
+		name    string
+		url     string
+		data    map[string][]byte
+		wantErr bool
+	}{{
+		name: "valid private key",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, "foo"),
+			AuthSecretPassphraseField: []byte("foo"),
+		},
+	}, {
+		name: "wrong passphrase",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, "foo"),
+			AuthSecretPassphraseField: []byte("bar"),
+		},
+		wantErr: true,
+	}, {
+		name: "invalid private key",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField: []byte("foo"),
+		},
+		wantErr: true,
+	}, {
+		name: "invalid known hosts",
+		url:  "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField:   rsaPrivateKeyPEM(t, ""),
+			AuthSecretKnownHostsField: []byte("127.0.0.1 ssh-ed25519 foo"),
+		},
+		wantErr: true,
+	}, {
+		name: "http repository",
+		url:  "https://github.com/kuberik/kuberik.git",
+		data: map[string][]byte{
+			AuthSecretIdentityField: []byte("foo"),
+		},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "auth",
+					Namespace: "default",
+				},
+				Data: tc.data,
+			}
+			client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+			repository := Repository{
+				URL: tc.url,
+				Auth: &RepositoryAuth{
+					SecretRef: corev1.LocalObjectReference{Name: secret.Name},
+				},
+			}
+
+			err := repository.validateAuth(context.TODO(), client, secret.Namespace)
+			if tc.wantErr {
+				assert.Check(t, err != nil, "expected an error")
+			} else {
+				assert.NilError(t, err)
+			}
+		})
+	}
+
+	// The secret can be created after the repository is referenced
+	repository := Repository{
+		URL: "ssh://git@127.0.0.1:2222/kuberik/kuberik.git",
+		Auth: &RepositoryAuth{
+			SecretRef: corev1.LocalObjectReference{Name: "auth"},
+		},
+	}
+	assert.NilError(t, repository.validateAuth(context.TODO(), fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(), "default"))
+}
+
+func TestKnownHostsCallbackCached(t *testing.T) {
+	hostKey := hostPublicKey(t)
+	knownHosts := []byte(fmt.Sprintf("[127.0.0.1]:2222 %s", ssh.MarshalAuthorizedKey(hostKey)))
+
+	cachedCallbacks := len(knownHostsCallbacks.callbacks)
+	_, err := knownHostsCallback(knownHosts)
+	assert.NilError(t, err)
+	_, err = knownHostsCallback(knownHosts)
+	assert.NilError(t, err)
+	assert.Equal(t, len(knownHostsCallbacks.callbacks), cachedCallbacks+1)
+
+	other, err := knownHostsCallback([]byte(fmt.Sprintf("[127.0.0.1]:2222 %s", ssh.MarshalAuthorizedKey(hostPublicKey(t)))))
+	assert.NilError(t, err)
+	assert.Check(t, other("127.0.0.1:2222", &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}, hostKey) != nil, "expected host key of other known hosts to be rejected")
+}
+
+type fakeGitHubAppTokenProvider struct{}
+
+func (p *fakeGitHubAppTokenProvider) InstallationToken(ctx context.Context, appID, installationID int64, privateKey []byte) (string, error) {
+	return fmt.Sprintf("token-%d-%d-%s", appID, installationID, privateKey), nil
+}
+
+func TestGetAuthMethodGitHubApp(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "auth",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			AuthSecretGitHubAppIDField:             []byte("1234"),
+			AuthSecretGitHubAppInstallationIDField: []byte("42"),
+			AuthSecretGitHubAppPrivateKeyField:     []byte("key"),
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+	repository := Repository{
+		URL: "https://github.com/kuberik/kuberik.git",
+		Auth: &RepositoryAuth{
+			SecretRef: corev1.LocalObjectReference{Name: secret.Name},
+		},
+	}
+
+	auth, authType, err := repository.GetAuthMethod(context.TODO(), client, secret.Namespace, &fakeGitHubAppTokenProvider{})
+	assert.NilError(t, err, "failed to get auth method")
+	assert.Equal(t, authType, RepositoryAuthTypeGitHubApp)
+	assert.DeepEqual(t, auth, &http.BasicAuth{Username: "x-access-token", Password: "token-1234-42-key"})
+
+	_, _, err = repository.GetAuthMethod(context.TODO(), client, secret.Namespace, nil)
+	assert.ErrorContains(t, err, "not supported")
+
+	secret.Data[AuthSecretGitHubAppInstallationIDField] = []byte("foo")
+	assert.NilError(t, client.Update(context.TODO(), secret))
+	_, _, err = repository.GetAuthMethod(context.TODO(), client, secret.Namespace, &fakeGitHubAppTokenProvider{})
+	assert.ErrorContains(t, err, "invalid githubAppInstallationID")
+}
@@ -26,6 +26,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoRollbackPolicy) DeepCopyInto(out *AutoRollbackPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoRollbackPolicy.
+func (in *AutoRollbackPolicy) DeepCopy() *AutoRollbackPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoRollbackPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Live) DeepCopyInto(out *Live) {
 	*out = *in
@@ -53,13 +68,43 @@ func (in *Live) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveApplySpec) DeepCopyInto(out *LiveApplySpec) {
+	*out = *in
+	if in.ReconcileTimeout != nil {
+		in, out := &in.ReconcileTimeout, &out.ReconcileTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PruneTimeout != nil {
+		in, out := &in.PruneTimeout, &out.PruneTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.WaitForReady != nil {
+		in, out := &in.WaitForReady, &out.WaitForReady
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveApplySpec.
+func (in *LiveApplySpec) DeepCopy() *LiveApplySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveApplySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveDeployment) DeepCopyInto(out *LiveDeployment) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeployment.
@@ -86,7 +131,7 @@ func (in *LiveDeploymentGroup) DeepCopyInto(out *LiveDeploymentGroup) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeploymentGroup.
@@ -107,6 +152,21 @@ func (in *LiveDeploymentGroup) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveDeploymentGroupBranchStatus) DeepCopyInto(out *LiveDeploymentGroupBranchStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeploymentGroupBranchStatus.
+func (in *LiveDeploymentGroupBranchStatus) DeepCopy() *LiveDeploymentGroupBranchStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveDeploymentGroupBranchStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveDeploymentGroupList) DeepCopyInto(out *LiveDeploymentGroupList) {
 	*out = *in
@@ -162,6 +222,22 @@ func (in *LiveDeploymentGroupSpec) DeepCopy() *LiveDeploymentGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveDeploymentGroupStatus) DeepCopyInto(out *LiveDeploymentGroupStatus) {
 	*out = *in
+	if in.LastPollTime != nil {
+		in, out := &in.LastPollTime, &out.LastPollTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Branches != nil {
+		in, out := &in.Branches, &out.Branches
+		*out = make([]LiveDeploymentGroupBranchStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeploymentGroupStatus.
@@ -206,14 +282,40 @@ func (in *LiveDeploymentList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveDeploymentRevision) DeepCopyInto(out *LiveDeploymentRevision) {
+	*out = *in
+	in.DeployTime.DeepCopyInto(&out.DeployTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeploymentRevision.
+func (in *LiveDeploymentRevision) DeepCopy() *LiveDeploymentRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveDeploymentRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveDeploymentSpec) DeepCopyInto(out *LiveDeploymentSpec) {
 	*out = *in
+	if in.Tag != nil {
+		in, out := &in.Tag, &out.Tag
+		*out = new(TagSelector)
+		**out = **in
+	}
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
 		*out = new(LiveTemplate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AutoRollback != nil {
+		in, out := &in.AutoRollback, &out.AutoRollback
+		*out = new(AutoRollbackPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeploymentSpec.
@@ -229,6 +331,24 @@ func (in *LiveDeploymentSpec) DeepCopy() *LiveDeploymentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveDeploymentStatus) DeepCopyInto(out *LiveDeploymentStatus) {
 	*out = *in
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]LiveDeploymentRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastPollTime != nil {
+		in, out := &in.LastPollTime, &out.LastPollTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveDeploymentStatus.
@@ -241,6 +361,27 @@ func (in *LiveDeploymentStatus) DeepCopy() *LiveDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveHookStatus) DeepCopyInto(out *LiveHookStatus) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	out.LiveObjectStatus = in.LiveObjectStatus
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveHookStatus.
+func (in *LiveHookStatus) DeepCopy() *LiveHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveList) DeepCopyInto(out *LiveList) {
 	*out = *in
@@ -273,6 +414,21 @@ func (in *LiveList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveObjectStatus) DeepCopyInto(out *LiveObjectStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveObjectStatus.
+func (in *LiveObjectStatus) DeepCopy() *LiveObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LivePhase) DeepCopyInto(out *LivePhase) {
 	*out = *in
@@ -288,10 +444,35 @@ func (in *LivePhase) DeepCopy() *LivePhase {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LivePlannedObject) DeepCopyInto(out *LivePlannedObject) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LivePlannedObject.
+func (in *LivePlannedObject) DeepCopy() *LivePlannedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(LivePlannedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LiveSpec) DeepCopyInto(out *LiveSpec) {
 	*out = *in
 	in.Repository.DeepCopyInto(&out.Repository)
+	if in.Apply != nil {
+		in, out := &in.Apply, &out.Apply
+		*out = new(LiveApplySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReconcileInterval != nil {
+		in, out := &in.ReconcileInterval, &out.ReconcileInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveSpec.
@@ -314,6 +495,32 @@ func (in *LiveStatus) DeepCopyInto(out *LiveStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastApplyTime != nil {
+		in, out := &in.LastApplyTime, &out.LastApplyTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = make([]LivePlannedObject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]LiveObjectStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]LiveHookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]LiveWaveStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveStatus.
@@ -343,6 +550,21 @@ func (in *LiveTemplate) DeepCopy() *LiveTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LiveWaveStatus) DeepCopyInto(out *LiveWaveStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LiveWaveStatus.
+func (in *LiveWaveStatus) DeepCopy() *LiveWaveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LiveWaveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Repository) DeepCopyInto(out *Repository) {
 	*out = *in
@@ -351,6 +573,11 @@ func (in *Repository) DeepCopyInto(out *Repository) {
 		*out = new(RepositoryAuth)
 		**out = **in
 	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(RepositoryTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Repository.
@@ -378,3 +605,38 @@ func (in *RepositoryAuth) DeepCopy() *RepositoryAuth {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryTLSConfig) DeepCopyInto(out *RepositoryTLSConfig) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryTLSConfig.
+func (in *RepositoryTLSConfig) DeepCopy() *RepositoryTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagSelector) DeepCopyInto(out *TagSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagSelector.
+func (in *TagSelector) DeepCopy() *TagSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(TagSelector)
+	in.DeepCopyInto(out)
+	return out
+}
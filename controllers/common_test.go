@@ -1,9 +1,26 @@
 package controllers
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/kuberik/kuberik/pkg/githubapp"
 )
 
 func getGithubTokenOrSkip() string {
@@ -13,3 +30,158 @@ func getGithubTokenOrSkip() string {
 	}
 	return token
 }
+
+// generateSSHIdentity generates a PEM encoded private key and its public key.
+func generateSSHIdentity() ([]byte, ssh.PublicKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), sshPublicKey, nil
+}
+
+// newFakeGitHubAPI starts a server which mints installation tokens for any GitHub App installation.
+func newFakeGitHubAPI() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var installationID int64
+		if _, err := fmt.Sscanf(r.URL.Path, "/app/installations/%d/access_tokens", &installationID); err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(githubapp.InstallationToken{
+			Token:     fmt.Sprintf("installation-token-%d", installationID),
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+	}))
+}
+
+// sshGitServer is a minimal SSH server serving git repositories from the local
+// filesystem using the git binary.
+type sshGitServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+}
+
+func startSSHGitServer(authorizedKey ssh.PublicKey) (*sshGitServer, error) {
+	_, hostPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorizedKey.Marshal()) {
+				return nil, fmt.Errorf("unknown public key for %s", conn.User())
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	server := &sshGitServer{
+		listener: listener,
+		config:   config,
+		hostKey:  hostSigner.PublicKey(),
+	}
+	go server.serve()
+	return server, nil
+}
+
+func (s *sshGitServer) URL(repoDir string) string {
+	return fmt.Sprintf("ssh://git@%s%s", s.listener.Addr().String(), repoDir)
+}
+
+func (s *sshGitServer) KnownHosts() string {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return fmt.Sprintf("[%s]:%d %s", addr.IP.String(), addr.Port, ssh.MarshalAuthorizedKey(s.hostKey))
+}
+
+func (s *sshGitServer) Close() error {
+	return s.listener.Close()
+}
+
+func (s *sshGitServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *sshGitServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleSession(channel, requests)
+	}
+}
+
+func (s *sshGitServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		if req.Type != "exec" || len(req.Payload) < 4 {
+			req.Reply(false, nil)
+			continue
+		}
+		command := string(req.Payload[4:])
+		args := strings.SplitN(command, " ", 2)
+		if len(args) != 2 || (args[0] != "git-upload-pack" && args[0] != "git-receive-pack") {
+			req.Reply(false, nil)
+			continue
+		}
+		req.Reply(true, nil)
+
+		cmd := exec.Command("git", strings.TrimPrefix(args[0], "git-"), strings.Trim(args[1], "'"))
+		cmd.Stdout = channel
+		cmd.Stderr = channel.Stderr()
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return
+		}
+		go func() {
+			io.Copy(stdin, channel)
+			stdin.Close()
+		}()
+
+		exitStatus := make([]byte, 4)
+		if err := cmd.Run(); err != nil {
+			binary.BigEndian.PutUint32(exitStatus, 1)
+		}
+		channel.SendRequest("exit-status", false, exitStatus)
+		return
+	}
+}
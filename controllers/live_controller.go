@@ -20,16 +20,22 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/go-git/go-git/v5/plumbing"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/rest"
 
 	// "k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -44,15 +50,26 @@ import (
 
 const LiveDestroyFinalizer = "kuberik.io/live-destroy"
 
+// healthWatchRetryInterval is the delay before restarting a failed health watch
+const healthWatchRetryInterval = 30 * time.Second
+
 // LiveReconciler reconciles a Live object
 type LiveReconciler struct {
 	client.Client
-	Scheme          *runtime.Scheme
-	Config          *rest.Config
-	RepoDir         string
-	ApplyResults    map[types.NamespacedName]<-chan error
-	DeleteResults   map[types.NamespacedName]<-chan error
+	Scheme *runtime.Scheme
+	Config *rest.Config
+	// Repositories caches the git repositories shared with the other reconcilers
+	Repositories *repository.Cache
+	// Executor runs the applies and destroys of the reconciled Lives
+	Executor *livepkg.Executor
+	// HealthWatches holds the watches of the status of the objects applied by the reconciled Lives
+	HealthWatches   map[types.NamespacedName]*livepkg.HealthWatch
 	KptClientEvents chan event.GenericEvent
+	GitHubAppTokens kuberikiov1alpha1.GitHubAppTokenProvider
+	// MaxConcurrentReconciles is the maximum number of Lives reconciled at the same time
+	MaxConcurrentReconciles int
+
+	healthWatchesMu sync.Mutex
 }
 
 //+kubebuilder:rbac:groups=kuberik.io,resources=lives,verbs=get;list;watch;create;update;patch;delete
@@ -76,6 +93,8 @@ func (r *LiveReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	err := r.Client.Get(ctx, req.NamespacedName, live)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			r.stopHealthWatch(req.NamespacedName)
+			r.Executor.Remove(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, fmt.Errorf("failed fetching live resource: %v", err)
@@ -92,10 +111,94 @@ func (r *LiveReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 
 	if live.DeletionTimestamp != nil {
+		r.stopHealthWatch(live.NamespacedName())
 		return r.ReconcileDelete(ctx, live)
 	}
 
-	return ctrl.Result{}, nil
+	remaining, periodic := live.ReconcileIntervalRemaining()
+	if periodic && remaining == 0 {
+		logger.Info("Re-applying resources after reconcile interval")
+		return r.ReconcileApply(ctx, live)
+	}
+
+	result, err := r.ReconcileHealth(ctx, live)
+	if periodic && (result.RequeueAfter == 0 || remaining < result.RequeueAfter) {
+		result.RequeueAfter = remaining
+	}
+	return result, err
+}
+
+// ReconcileHealth keeps watching the status of the applied objects and records it in the status of the Live
+func (r *LiveReconciler) ReconcileHealth(ctx context.Context, live *kuberikiov1alpha1.Live) (ctrl.Result, error) {
+	if live.Spec.DryRun {
+		r.stopHealthWatch(live.NamespacedName())
+		return ctrl.Result{}, nil
+	}
+
+	r.healthWatchesMu.Lock()
+	watch, ok := r.HealthWatches[live.NamespacedName()]
+	r.healthWatchesMu.Unlock()
+	if ok && watch.Stopped() {
+		r.forgetHealthWatch(live.NamespacedName(), watch)
+		if err := watch.Err(); err != nil {
+			log.FromContext(ctx).Error(err, "health watch failed")
+			original := live.DeepCopy()
+			live.SetHealthUnknown(err)
+			if err := r.updateStatusIfChanged(ctx, original, live); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: healthWatchRetryInterval}, nil
+		}
+		ok = false
+	}
+	if !ok {
+		kptClient, err := r.GetKptClient(ctx, *live)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create kpt client: %v", err)
+		}
+		trigger := live.DeepCopy()
+		watch, err = kptClient.WatchHealth(live.NamespacedName(), live.InventoryID(), func() {
+			r.KptClientEvents <- event.GenericEvent{Object: trigger}
+		})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to watch health: %v", err)
+		}
+		r.healthWatchesMu.Lock()
+		r.HealthWatches[live.NamespacedName()] = watch
+		r.healthWatchesMu.Unlock()
+	}
+
+	health, synced := watch.Health()
+	if !synced {
+		return ctrl.Result{}, nil
+	}
+	original := live.DeepCopy()
+	live.SetHealth(health)
+	return ctrl.Result{}, r.updateStatusIfChanged(ctx, original, live)
+}
+
+func (r *LiveReconciler) updateStatusIfChanged(ctx context.Context, original, live *kuberikiov1alpha1.Live) error {
+	if equality.Semantic.DeepEqual(original.Status, live.Status) {
+		return nil
+	}
+	return r.Client.Status().Update(ctx, live)
+}
+
+func (r *LiveReconciler) stopHealthWatch(namespacedName types.NamespacedName) {
+	r.healthWatchesMu.Lock()
+	defer r.healthWatchesMu.Unlock()
+	if watch, ok := r.HealthWatches[namespacedName]; ok {
+		watch.Stop()
+		delete(r.HealthWatches, namespacedName)
+	}
+}
+
+func (r *LiveReconciler) forgetHealthWatch(namespacedName types.NamespacedName, watch *livepkg.HealthWatch) {
+	r.healthWatchesMu.Lock()
+	defer r.healthWatchesMu.Unlock()
+	if r.HealthWatches[namespacedName] == watch {
+		delete(r.HealthWatches, namespacedName)
+	}
 }
 
 func (r *LiveReconciler) SetFinalizers(ctx context.Context, live *kuberikiov1alpha1.Live) error {
@@ -112,60 +215,142 @@ func (r *LiveReconciler) SetFinalizers(ctx context.Context, live *kuberikiov1alp
 	return r.Update(ctx, live)
 }
 
-func (r *LiveReconciler) ReconcileChanResult(
-	ctx context.Context,
-	live *kuberikiov1alpha1.Live,
-	results map[types.NamespacedName]<-chan error,
-	reconcile func(error) error,
-) error {
-	namespacedName := live.NamespacedName()
-	select {
-	case result := <-results[namespacedName]:
-		if err := reconcile(result); err != nil {
-			ch := make(chan error, 1)
-			ch <- result
-			close(ch)
-			results[namespacedName] = ch
-			return err
+func (r *LiveReconciler) ReconcileApply(ctx context.Context, live *kuberikiov1alpha1.Live) (ctrl.Result, error) {
+	var interrupted string
+	if job := r.Executor.Get(live.NamespacedName(), livepkg.JobKindApply); job != nil {
+		if !job.Done() {
+			if job.Generation != live.Generation && live.Spec.Interruptible {
+				// The result of the running apply is discarded once it stops,
+				// after which the new generation gets applied
+				log.FromContext(ctx).Info("Interrupting apply of outdated generation", "attempt", job.ID, "generation", job.Generation)
+				r.Executor.Cancel(live.NamespacedName(), livepkg.JobKindApply)
+			}
+			if job.ID == live.Status.ApplyAttemptID {
+				original := live.DeepCopy()
+				live.Status.Waves = livepkg.WaveStatuses(job.Waves())
+				return ctrl.Result{}, r.updateStatusIfChanged(ctx, original, live)
+			}
+			return ctrl.Result{}, nil
 		}
-		delete(results, namespacedName)
-	default:
+		if job.ID == live.Status.ApplyAttemptID && job.Generation == live.Generation && !job.Cancelled() {
+			return ctrl.Result{}, r.recordApplyResult(ctx, live, job)
+		}
+		switch {
+		case job.ID != live.Status.ApplyAttemptID || job.Generation == live.Generation:
+			log.FromContext(ctx).Info("Discarding result of superseded apply attempt", "attempt", job.ID, "currentAttempt", live.Status.ApplyAttemptID)
+		case job.Cancelled():
+			interrupted = fmt.Sprintf("apply attempt %s of generation %d was interrupted by generation %d", job.ID, job.Generation, live.Generation)
+		default:
+			// The Live wasn't interruptible, so the apply of the previous generation, e.g. a re-apply, completed
+			// and its result is recorded before the new generation is applied
+			setJobStatus(live, job)
+			if err := r.Client.Status().Update(ctx, live); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		r.Executor.Forget(live.NamespacedName(), livepkg.JobKindApply, job)
 	}
-	return nil
-}
 
-func (r *LiveReconciler) ReconcileApply(ctx context.Context, live *kuberikiov1alpha1.Live) (ctrl.Result, error) {
-	if _, ok := r.ApplyResults[live.NamespacedName()]; ok {
-		return ctrl.Result{}, r.ReconcileChanResult(ctx, live, r.ApplyResults, func(err error) error {
-			if err != nil {
-				live.SetPhase(kuberikiov1alpha1.LivePhase{
-					Name:               kuberikiov1alpha1.LivePhaseFailed,
-					ApplyResultMessage: err.Error(),
-				})
-			} else {
-				live.SetPhase(kuberikiov1alpha1.LivePhase{Name: kuberikiov1alpha1.LivePhaseSucceeded})
-			}
-			return r.Client.Status().Update(ctx, live)
-		})
+	if interrupted == "" && live.IsApplying() && live.Status.ApplyAttemptID != "" {
+		// The apply attempt recorded in the status isn't tracked by this controller,
+		// so it was interrupted by a restart of the controller before completing
+		interrupted = fmt.Sprintf("apply attempt %s was interrupted before completing", live.Status.ApplyAttemptID)
+	}
+	if interrupted != "" {
+		log.FromContext(ctx).Info("Re-applying resources after interrupted apply attempt", "attempt", live.Status.ApplyAttemptID)
 	}
 
-	auth, err := live.Spec.GetAuthMethod(ctx, r.Client, live.Namespace)
+	repo, authType, release, err := openRepository(ctx, r.Client, r.Repositories, live.Spec.Repository, live.Namespace, r.GitHubAppTokens)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to get auth method: %v", err)
+		return ctrl.Result{}, err
+	}
+	defer release()
+
+	build, err := r.buildCommit(repo, live, live.Spec.Commit)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.InstallResourceGroup(ctx); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to install resource group: %v", err)
 	}
-	repo, err := repository.InitGitRepository(path.Join(r.RepoDir, live.Namespace, live.Name), live.Spec.Repository.URL, auth)
+
+	apply, err := livepkg.NewLiveApply(live, build.ResMap)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to init git repository: %v", err)
+		return ctrl.Result{}, fmt.Errorf("failed to apply resources: %v", err)
+	}
+
+	var previous *livepkg.LiveApply
+	if !live.Spec.DryRun && apply.HasRollbackHooks() && live.Status.LastAppliedCommit != "" && live.Status.LastAppliedCommit != live.Spec.Commit {
+		previousBuild, err := r.buildCommit(repo, live, live.Status.LastAppliedCommit)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to build previous revision: %v", err)
+		}
+		previous, err = livepkg.NewLiveApply(live, previousBuild.ResMap)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to apply previous revision: %v", err)
+		}
+	}
+
+	r.stopHealthWatch(live.NamespacedName())
+	attemptID := string(uuid.NewUUID())
+	live.Status.ApplyAttemptID = attemptID
+	live.Status.Waves = nil
+	if live.Reconciled() {
+		// The applied generation is only re-applied after the reconcile interval, so the Live stays ready
+		live.SetReapplying()
+	} else {
+		live.SetPhase(kuberikiov1alpha1.LivePhase{Name: kuberikiov1alpha1.LivePhaseApplying})
+		if interrupted != "" {
+			live.SetApplyInterrupted(interrupted)
+		}
 	}
+	live.SetRepositoryAuth(authType)
+	if err := r.Client.Status().Update(ctx, live); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set state to applying: %v", err)
+	}
+
+	applyOptions := livepkg.NewApplyOptions(live)
+	job := &livepkg.Job{ID: attemptID, Generation: live.Generation, DryRun: live.Spec.DryRun}
+	applyOptions.OnWaveProgress = func(waves []livepkg.WaveProgress) {
+		job.SetWaves(waves)
+		select {
+		case r.KptClientEvents <- event.GenericEvent{Object: live}:
+		default:
+			// The progress is recorded with the next reconcile anyway
+		}
+	}
+	r.Executor.Submit(live.NamespacedName(), livepkg.JobKindApply, job, func(ctx context.Context, job *livepkg.Job) {
+		kptClient, err := r.GetKptClient(ctx, *live)
+		if err != nil {
+			job.Err = fmt.Errorf("failed to create apply client: %v", err)
+			return
+		}
+		if job.DryRun {
+			job.Plan, job.Err = kptClient.Plan(apply.ResMap, applyOptions)
+			return
+		}
+		job.Objects, job.Hooks, job.Err = kptClient.ApplyWithHooks(apply, previous, applyOptions)
+	}, func() {
+		r.KptClientEvents <- event.GenericEvent{Object: live}
+	})
+
+	return ctrl.Result{}, nil
+}
 
-	err = repo.FetchCommit(live.Spec.Commit)
+// buildCommit builds the kustomize layer of the Live at the commit
+func (r *LiveReconciler) buildCommit(repo *repository.GitRepository, live *kuberikiov1alpha1.Live, commit string) (*kustomize.KustomizeBuild, error) {
+	err := repo.FetchCommit(commit)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to fetch commit: %v", err)
+		return nil, fmt.Errorf("failed to fetch commit: %v", err)
 	}
 
-	commitDir, err := repo.CreateCommitDir(plumbing.NewHash(live.Spec.Commit))
+	commitDir, err := repo.CreateCommitDir(plumbing.NewHash(commit), repository.CheckoutOptions{
+		Submodules: live.Spec.Repository.Submodules,
+		LFS:        live.Spec.Repository.LFS,
+	})
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to commit dir: %v", err)
+		return nil, fmt.Errorf("failed to commit dir: %v", err)
 	}
 
 	baseLayer := kustomize.Layer{
@@ -181,72 +366,107 @@ func (r *LiveReconciler) ReconcileApply(ctx context.Context, live *kuberikiov1al
 		}
 		transformOverlayLayer, err := transformOverlay.CreateLayeredFilesystemLayer()
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to create transform overlay: %v", err)
+			return nil, fmt.Errorf("failed to create transform overlay: %v", err)
 		}
 		buildLayer = *transformOverlayLayer
 	}
 
 	build, err := buildLayer.Build()
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("kustomize build failed: %v", err)
+		return nil, fmt.Errorf("kustomize build failed: %v", err)
 	}
+	return build, nil
+}
 
-	if err := r.InstallResourceGroup(ctx); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to install resource group: %v", err)
+// recordApplyResult records the result of the completed apply job in the status of the Live
+func (r *LiveReconciler) recordApplyResult(ctx context.Context, live *kuberikiov1alpha1.Live, job *livepkg.Job) error {
+	if live.Reconciled() {
+		live.SetReapplied(job.Err)
+	} else if job.Err != nil {
+		live.SetPhase(kuberikiov1alpha1.LivePhase{
+			Name:               kuberikiov1alpha1.LivePhaseFailed,
+			ApplyResultMessage: job.Err.Error(),
+			DryRun:             job.DryRun,
+		})
+	} else {
+		live.SetPhase(kuberikiov1alpha1.LivePhase{Name: kuberikiov1alpha1.LivePhaseSucceeded, DryRun: job.DryRun})
 	}
-
-	apply, err := livepkg.NewLiveApply(live, build.ResMap)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to apply resources: %v", err)
+	setJobStatus(live, job)
+	if !job.DryRun && job.Err == nil {
+		live.Status.LastAppliedCommit = live.Spec.Commit
 	}
-
-	live.SetPhase(kuberikiov1alpha1.LivePhase{Name: kuberikiov1alpha1.LivePhaseApplying})
 	if err := r.Client.Status().Update(ctx, live); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to set state to applying: %v", err)
+		return err
 	}
+	r.Executor.Forget(live.NamespacedName(), livepkg.JobKindApply, job)
+	return nil
+}
 
-	result := make(chan error, 1)
-	r.ApplyResults[live.NamespacedName()] = result
-	kptClient, err := r.GetKptClient(ctx, *live)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to create apply client: %v", err)
+// setJobStatus records the outcome of the objects of the completed apply job in the status of the Live
+func setJobStatus(live *kuberikiov1alpha1.Live, job *livepkg.Job) {
+	live.Status.LastApplyTime = &metav1.Time{Time: time.Now()}
+	live.Status.Plan = nil
+	if job.DryRun {
+		live.Status.Plan = livepkg.PlanStatus(job.Plan)
+		return
 	}
-	go func() {
-		// TODO: Set options from LiveSpec
-		result <- kptClient.Apply(apply.ResMap, livepkg.ApplyOptions{})
-		close(result)
-		r.KptClientEvents <- event.GenericEvent{Object: live}
-	}()
-
-	return ctrl.Result{}, nil
+	live.Status.Objects = livepkg.ObjectStatuses(job.Objects)
+	live.Status.ObjectCount = len(job.Objects)
+	live.Status.Hooks = livepkg.HookStatuses(job.Hooks)
+	live.Status.Waves = livepkg.WaveStatuses(job.Waves())
 }
 
 func (r *LiveReconciler) ReconcileDelete(ctx context.Context, live *kuberikiov1alpha1.Live) (ctrl.Result, error) {
-	namespacedName := types.NamespacedName{Name: live.Name, Namespace: live.Namespace}
-	if _, ok := r.DeleteResults[namespacedName]; !ok {
-		kptClient, err := r.GetKptClient(ctx, *live)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to create kpt client: %v", err)
+	if applyJob := r.Executor.Get(live.NamespacedName(), livepkg.JobKindApply); applyJob != nil {
+		if !applyJob.Done() {
+			// The running apply could recreate the objects after they're destroyed, so it has to stop first.
+			// The Live is reconciled again once the apply job is done.
+			log.FromContext(ctx).Info("Cancelling apply before destroying resources", "attempt", applyJob.ID)
+			r.Executor.Cancel(live.NamespacedName(), livepkg.JobKindApply)
+			return ctrl.Result{}, nil
 		}
-		result := make(chan error, 1)
-		r.DeleteResults[namespacedName] = result
-		go func() {
-			result <- kptClient.Destroy(namespacedName, live.InventoryID())
-			close(result)
-			r.KptClientEvents <- event.GenericEvent{Object: live}
-		}()
+		r.Executor.Forget(live.NamespacedName(), livepkg.JobKindApply, applyJob)
 	}
 
-	return ctrl.Result{}, r.ReconcileChanResult(ctx, live, r.DeleteResults, func(err error) error {
-		finalizers := []string{}
-		for _, f := range live.Finalizers {
-			if f != LiveDestroyFinalizer {
-				finalizers = append(finalizers, f)
+	job := r.Executor.Get(live.NamespacedName(), livepkg.JobKindDestroy)
+	if job == nil {
+		job = &livepkg.Job{Generation: live.Generation}
+		r.Executor.Submit(live.NamespacedName(), livepkg.JobKindDestroy, job, func(ctx context.Context, job *livepkg.Job) {
+			kptClient, err := r.GetKptClient(ctx, *live)
+			if err != nil {
+				job.Err = fmt.Errorf("failed to create kpt client: %v", err)
+				return
 			}
+			for _, phase := range livepkg.HookPhases {
+				if err := kptClient.Destroy(livepkg.HookInventory(live.NamespacedName(), live.InventoryID(), phase)); err != nil {
+					job.Err = fmt.Errorf("failed to destroy %s hooks: %v", phase, err)
+					return
+				}
+			}
+			job.Err = kptClient.Destroy(live.NamespacedName(), live.InventoryID())
+		}, func() {
+			r.KptClientEvents <- event.GenericEvent{Object: live}
+		})
+	}
+	if !job.Done() {
+		return ctrl.Result{}, nil
+	}
+	if job.Err != nil {
+		log.FromContext(ctx).Error(job.Err, "failed to destroy resources")
+	}
+
+	finalizers := []string{}
+	for _, f := range live.Finalizers {
+		if f != LiveDestroyFinalizer {
+			finalizers = append(finalizers, f)
 		}
-		live.Finalizers = finalizers
-		return r.Client.Update(ctx, live)
-	})
+	}
+	live.Finalizers = finalizers
+	if err := r.Client.Update(ctx, live); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.Executor.Forget(live.NamespacedName(), livepkg.JobKindDestroy, job)
+	return ctrl.Result{}, nil
 }
 
 func (r *LiveReconciler) InstallResourceGroup(ctx context.Context) error {
@@ -274,6 +494,7 @@ func (r *LiveReconciler) GetKptClient(ctx context.Context, live kuberikiov1alpha
 func (r *LiveReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	build, err := ctrl.NewControllerManagedBy(mgr).
 		For(&kuberikiov1alpha1.Live{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Build(r)
 	if err != nil {
 		return err
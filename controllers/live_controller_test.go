@@ -2,6 +2,10 @@ package controllers
 
 import (
 	"context"
+	cryptorand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"io/fs"
 	"testing/fstest"
@@ -20,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -164,6 +169,8 @@ var _ = Describe("Live controller", func() {
 		var liveLookupKey *types.NamespacedName
 		var gitFiles fstest.MapFS
 		var transformers string
+		var reconcileInterval *metav1.Duration
+		var interruptible bool
 		var commit plumbing.Hash
 		var repo *git.Repository
 		testCaseCounter := 0
@@ -256,8 +263,10 @@ var _ = Describe("Live controller", func() {
 					Repository: kuberikiov1alpha1.Repository{
 						URL: fmt.Sprintf("file://%s", repoDir),
 					},
-					Commit:       commit.String(),
-					Transformers: transformers,
+					Commit:            commit.String(),
+					Transformers:      transformers,
+					ReconcileInterval: reconcileInterval,
+					Interruptible:     interruptible,
 				},
 			}
 			Expect(k8sClient.Create(ctx, live)).Should(Succeed())
@@ -298,6 +307,459 @@ resources: [pod.yaml]
 				By("By waiting for Pod to reconcile")
 				setPodPhaseComplete(podLookupKey)
 				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, true)
+
+				By("By checking the per-object results")
+				live := &kuberikiov1alpha1.Live{}
+				Expect(k8sClient.Get(ctx, *liveLookupKey, live)).Should(Succeed())
+				Expect(live.Status.ObjectCount).Should(Equal(1))
+				Expect(live.Status.Objects).Should(ConsistOf(SatisfyAll(
+					HaveField("Version", "v1"),
+					HaveField("Kind", "Pod"),
+					HaveField("Namespace", "default"),
+					HaveField("Name", "live-pod-success"),
+					HaveField("Apply", "Successful"),
+					HaveField("Wait", "Successful"),
+				)))
+
+				By("By waiting for the Live to become healthy")
+				Eventually(func() (*metav1.Condition, error) {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return nil, err
+					}
+					return meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionHealthy)), nil
+				}, timeout, interval).Should(SatisfyAll(
+					Not(BeNil()),
+					HaveField("Status", metav1.ConditionTrue),
+					HaveField("Reason", "Current"),
+				))
+
+				By("By deleting the Pod")
+				Expect(k8sClient.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podLookupKey.Name, Namespace: podLookupKey.Namespace}})).Should(Succeed())
+				Eventually(func() (*metav1.Condition, error) {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return nil, err
+					}
+					return meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionHealthy)), nil
+				}, timeout, interval).Should(SatisfyAll(
+					Not(BeNil()),
+					HaveField("Status", metav1.ConditionFalse),
+				))
+			})
+		})
+		When("Apply attempt isn't tracked by the controller", func() {
+			BeforeEach(func() {
+				gitFiles = fstest.MapFS{
+					"pod.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-pod-interrupted
+  namespace: default
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [pod.yaml]
+`)},
+				}
+			})
+			It("Should apply the resources again", func() {
+				podLookupKey := types.NamespacedName{Name: "live-pod-interrupted", Namespace: liveLookupKey.Namespace}
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseApplying), metav1.ConditionFalse, false)
+
+				By("By replacing the apply attempt as if it was started before a controller restart")
+				Eventually(func() error {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return err
+					}
+					live.Status.ApplyAttemptID = "interrupted-attempt"
+					return k8sClient.Status().Update(ctx, live)
+				}, timeout, interval).Should(Succeed())
+
+				By("By waiting for the resources to be applied again")
+				setPodPhaseComplete(podLookupKey)
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+				live := &kuberikiov1alpha1.Live{}
+				Expect(k8sClient.Get(ctx, *liveLookupKey, live)).Should(Succeed())
+				Expect(live.Status.ApplyAttemptID).ShouldNot(Or(BeEmpty(), Equal("interrupted-attempt")))
+			})
+		})
+		When("Resources are applied in sync waves", func() {
+			BeforeEach(func() {
+				gitFiles = fstest.MapFS{
+					"resources.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-pod-waves
+  namespace: default
+  annotations:
+    kuberik.io/sync-wave: "1"
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: live-configmap-waves
+  namespace: default
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [resources.yaml]
+`)},
+				}
+			})
+			It("Should report the progress of the waves", func() {
+				podLookupKey := types.NamespacedName{Name: "live-pod-waves", Namespace: liveLookupKey.Namespace}
+				getWaves := func() ([]kuberikiov1alpha1.LiveWaveStatus, error) {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return nil, err
+					}
+					return live.Status.Waves, nil
+				}
+
+				By("By waiting for the second wave to be applied")
+				Eventually(getWaves, timeout, interval).Should(Equal([]kuberikiov1alpha1.LiveWaveStatus{
+					{Wave: 0, Phase: "Completed", Objects: 1, Applied: 1, Reconciled: 1},
+					{Wave: 1, Phase: "InProgress", Objects: 1, Applied: 1},
+				}))
+
+				By("By waiting for the second wave to reconcile")
+				setPodPhaseComplete(podLookupKey)
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+				Expect(getWaves()).Should(Equal([]kuberikiov1alpha1.LiveWaveStatus{
+					{Wave: 0, Phase: "Completed", Objects: 1, Applied: 1, Reconciled: 1},
+					{Wave: 1, Phase: "Completed", Objects: 1, Applied: 1, Reconciled: 1},
+				}))
+			})
+		})
+		When("Resources have hooks", func() {
+			BeforeEach(func() {
+				gitFiles = fstest.MapFS{
+					"pod.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-pod-hooks
+  namespace: default
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+`)},
+					"hooks.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: live-hook-pre-apply
+  namespace: default
+  annotations:
+    kuberik.io/hook: pre-apply
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-hook-post-apply
+  namespace: default
+  annotations:
+    kuberik.io/hook: post-apply
+spec:
+  containers:
+  - name: smoke-test
+    image: busybox
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [pod.yaml, hooks.yaml]
+`)},
+				}
+			})
+			It("Should run the hooks around the apply", func() {
+				podLookupKey := types.NamespacedName{Name: "live-pod-hooks", Namespace: liveLookupKey.Namespace}
+				postApplyHookLookupKey := types.NamespacedName{Name: "live-hook-post-apply", Namespace: liveLookupKey.Namespace}
+
+				By("By waiting for the pre-apply hook to be applied before the resources")
+				Eventually(func() error {
+					return k8sClient.Get(ctx, types.NamespacedName{Name: "live-hook-pre-apply", Namespace: "default"}, &corev1.ConfigMap{})
+				}, timeout, interval).Should(Succeed())
+				setPodPhaseComplete(podLookupKey)
+
+				By("By waiting for the post-apply hook to complete")
+				setPodPhaseComplete(postApplyHookLookupKey)
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+
+				live := &kuberikiov1alpha1.Live{}
+				Expect(k8sClient.Get(ctx, *liveLookupKey, live)).Should(Succeed())
+				Expect(live.Status.Objects).Should(ConsistOf(HaveField("Name", "live-pod-hooks")))
+				Expect(live.Status.Hooks).Should(Equal([]kuberikiov1alpha1.LiveHookStatus{{
+					Phase:   "pre-apply",
+					Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+					LiveObjectStatus: kuberikiov1alpha1.LiveObjectStatus{
+						Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "live-hook-pre-apply",
+						Apply: "Successful", Wait: "Successful", Status: "Current", Message: "Resource is always ready",
+					},
+				}, {
+					Phase:   "post-apply",
+					Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+					LiveObjectStatus: kuberikiov1alpha1.LiveObjectStatus{
+						Version: "v1", Kind: "Pod", Namespace: "default", Name: "live-hook-post-apply",
+						Apply: "Successful", Wait: "Successful", Status: "Current", Message: "Pod has completed successfully",
+					},
+				}}))
+				Expect(live.Status.LastAppliedCommit).Should(Equal(commit.String()))
+			})
+		})
+		When("Post-apply hook fails", func() {
+			BeforeEach(func() {
+				gitFiles = fstest.MapFS{
+					"hooks.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-hook-post-apply-failed
+  namespace: default
+  annotations:
+    kuberik.io/hook: post-apply
+spec:
+  containers:
+  - name: smoke-test
+    image: busybox
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [hooks.yaml]
+`)},
+				}
+			})
+			It("Should fail the apply", func() {
+				hookLookupKey := types.NamespacedName{Name: "live-hook-post-apply-failed", Namespace: liveLookupKey.Namespace}
+				setPodPhaseCrashed(hookLookupKey, "smoke-test")
+
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseFailed), metav1.ConditionFalse, false)
+				live := &kuberikiov1alpha1.Live{}
+				Expect(k8sClient.Get(ctx, *liveLookupKey, live)).Should(Succeed())
+				Expect(meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionApplyResult))).Should(
+					HaveField("Message", ContainSubstring("post-apply hooks failed")),
+				)
+				Expect(live.Status.Hooks).Should(ConsistOf(SatisfyAll(
+					HaveField("Phase", "post-apply"),
+					HaveField("LiveObjectStatus.Name", "live-hook-post-apply-failed"),
+					HaveField("LiveObjectStatus.Wait", "Failed"),
+				)))
+				Expect(live.Status.LastAppliedCommit).Should(BeEmpty())
+			})
+		})
+		When("Interruptible Live is updated while applying", func() {
+			BeforeEach(func() {
+				interruptible = true
+				gitFiles = fstest.MapFS{
+					"pod.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-pod-interrupt
+  namespace: default
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [pod.yaml]
+`)},
+				}
+			})
+			AfterEach(func() {
+				interruptible = false
+			})
+			It("Should interrupt the apply and apply the new generation", func() {
+				podLookupKey := types.NamespacedName{Name: "live-pod-interrupt", Namespace: liveLookupKey.Namespace}
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseApplying), metav1.ConditionFalse, false)
+				Eventually(func() error {
+					return k8sClient.Get(ctx, podLookupKey, &corev1.Pod{})
+				}, timeout, interval).Should(Succeed())
+
+				By("By committing a new version of the resources")
+				worktree, err := repo.Worktree()
+				Expect(err).NotTo(HaveOccurred())
+				configMap, err := worktree.Filesystem.Create("configmap.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = configMap.Write([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: live-configmap-interrupt
+  namespace: default
+`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Close()).To(Succeed())
+				kustomization, err := worktree.Filesystem.Create("kustomization.yaml")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = kustomization.Write([]byte(`resources: [configmap.yaml]`))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kustomization.Close()).To(Succeed())
+				_, err = worktree.Add(".")
+				Expect(err).NotTo(HaveOccurred())
+				newCommit, err := commitWithDefaults(worktree)
+				Expect(err).NotTo(HaveOccurred())
+
+				var interruptedAttempt string
+				Eventually(func() error {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return err
+					}
+					interruptedAttempt = live.Status.ApplyAttemptID
+					live.Spec.Commit = newCommit.String()
+					return k8sClient.Update(ctx, live)
+				}, timeout, interval).Should(Succeed())
+
+				By("By waiting for the new generation to be applied")
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+				Eventually(func() error {
+					return k8sClient.Get(ctx, types.NamespacedName{Name: "live-configmap-interrupt", Namespace: "default"}, &corev1.ConfigMap{})
+				}, timeout, interval).Should(Succeed())
+
+				live := &kuberikiov1alpha1.Live{}
+				Expect(k8sClient.Get(ctx, *liveLookupKey, live)).Should(Succeed())
+				Expect(live.Status.ApplyAttemptID).ShouldNot(Equal(interruptedAttempt))
+			})
+			It("Should record the interruption", func() {
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseApplying), metav1.ConditionFalse, false)
+
+				Eventually(func() error {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return err
+					}
+					live.Spec.Path = "./"
+					return k8sClient.Update(ctx, live)
+				}, timeout, interval).Should(Succeed())
+
+				Eventually(func() *metav1.Condition {
+					live := &kuberikiov1alpha1.Live{}
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return nil
+					}
+					return meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionApplyResult))
+				}, timeout, interval).Should(And(
+					Not(BeNil()),
+					HaveField("Reason", "ApplyInterrupted"),
+					HaveField("Message", ContainSubstring("interrupted by generation 2")),
+				))
+			})
+		})
+		When("Reconcile interval is set", func() {
+			BeforeEach(func() {
+				reconcileInterval = &metav1.Duration{Duration: time.Second * 2}
+				gitFiles = fstest.MapFS{
+					"configmap.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: live-reconcile-interval
+  namespace: default
+data:
+  key: value
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [configmap.yaml]
+`)},
+				}
+			})
+			AfterEach(func() {
+				reconcileInterval = nil
+			})
+			It("Should revert changes made to the applied objects", func() {
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+
+				By("By deleting the applied ConfigMap")
+				configMapLookupKey := types.NamespacedName{Name: "live-reconcile-interval", Namespace: liveLookupKey.Namespace}
+				Expect(k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: configMapLookupKey.Name, Namespace: configMapLookupKey.Namespace}})).Should(Succeed())
+
+				By("By waiting for the ConfigMap to be re-applied")
+				Eventually(func() error {
+					return k8sClient.Get(ctx, configMapLookupKey, &corev1.ConfigMap{})
+				}, timeout, interval).Should(Succeed())
+
+				By("By checking the Live stays ready while re-applying")
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, true)
+				live := &kuberikiov1alpha1.Live{}
+				Expect(k8sClient.Get(ctx, *liveLookupKey, live)).Should(Succeed())
+				Expect(meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionReapplying))).ShouldNot(BeNil())
+			})
+		})
+		When("Live is deleted while re-applying", func() {
+			BeforeEach(func() {
+				reconcileInterval = &metav1.Duration{Duration: time.Second * 2}
+				gitFiles = fstest.MapFS{
+					"pod.yaml": {
+						Data: []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: live-pod-reapply-delete
+  namespace: default
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+`)},
+					"kustomization.yaml": {
+						Data: []byte(`
+resources: [pod.yaml]
+`)},
+				}
+			})
+			AfterEach(func() {
+				reconcileInterval = nil
+			})
+			It("Should stop the re-apply before destroying the resources", func() {
+				podLookupKey := types.NamespacedName{Name: "live-pod-reapply-delete", Namespace: liveLookupKey.Namespace}
+				setPodPhaseComplete(podLookupKey)
+				assertLiveReadyStatus(*liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+
+				By("By deleting the Pod, so that the re-apply keeps waiting for the recreated Pod")
+				Expect(k8sClient.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podLookupKey.Name, Namespace: podLookupKey.Namespace}})).Should(Succeed())
+				live := &kuberikiov1alpha1.Live{}
+				Eventually(func() bool {
+					if err := k8sClient.Get(ctx, *liveLookupKey, live); err != nil {
+						return false
+					}
+					return meta.IsStatusConditionTrue(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionReapplying))
+				}, timeout, interval).Should(BeTrue())
+
+				By("By deleting the Live")
+				Expect(k8sClient.Delete(ctx, live)).Should(Succeed())
+				Eventually(func() bool {
+					return errors.IsNotFound(k8sClient.Get(ctx, *liveLookupKey, &kuberikiov1alpha1.Live{}))
+				}, timeout, interval).Should(BeTrue())
+
+				By("By checking the Pod isn't recreated after the resources were destroyed")
+				getPod := func() bool {
+					return errors.IsNotFound(k8sClient.Get(ctx, podLookupKey, &corev1.Pod{}))
+				}
+				Eventually(getPod, timeout, interval).Should(BeTrue())
+				Consistently(getPod, time.Second*3, interval).Should(BeTrue())
 			})
 		})
 		When("Deployed resources reconcile fails", func() {
@@ -564,6 +1026,124 @@ configMapGenerator:
 		})
 	})
 
+	Context("Creating a Live with username and password credentials", func() {
+		It("Should report the credentials used in a condition", func() {
+			authSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "username-password-auth",
+					Namespace: "default",
+				},
+				StringData: map[string]string{
+					kuberikiov1alpha1.AuthSecretUsernameField: "john",
+					kuberikiov1alpha1.AuthSecretPasswordField: "secret",
+				},
+			}
+			Expect(k8sClient.Create(ctx, &authSecret)).Should(Succeed())
+
+			repoDir := GinkgoT().TempDir()
+			repo, err := generateGitRepository(repoDir, fstest.MapFS{
+				"kustomization.yaml": {Data: []byte(`
+configMapGenerator:
+- name: username-password-auth
+  namespace: default
+`)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			head, err := repo.Head()
+			Expect(err).NotTo(HaveOccurred())
+
+			live := &kuberikiov1alpha1.Live{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "username-password-auth",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveSpec{
+					Path: LivePath,
+					Repository: kuberikiov1alpha1.Repository{
+						URL: fmt.Sprintf("file://%s", repoDir),
+						Auth: &kuberikiov1alpha1.RepositoryAuth{
+							SecretRef: corev1.LocalObjectReference{
+								Name: authSecret.Name,
+							},
+						},
+					},
+					Commit: head.Hash().String(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, live)).Should(Succeed())
+
+			liveLookupKey := types.NamespacedName{Name: live.Name, Namespace: live.Namespace}
+			assertLiveReadyStatus(liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+			Expect(k8sClient.Get(ctx, liveLookupKey, live)).Should(Succeed())
+			Expect(meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionRepositoryAuth))).Should(SatisfyAll(
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", string(kuberikiov1alpha1.RepositoryAuthTypeUsernamePassword)),
+			))
+		})
+	})
+
+	Context("Creating a Live with GitHub App credentials", func() {
+		It("Should use installation token to access the repository", func() {
+			privateKey, err := rsa.GenerateKey(cryptorand.Reader, 2048)
+			Expect(err).NotTo(HaveOccurred())
+			authSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "github-app-auth",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					kuberikiov1alpha1.AuthSecretGitHubAppIDField:             []byte("1234"),
+					kuberikiov1alpha1.AuthSecretGitHubAppInstallationIDField: []byte("42"),
+					kuberikiov1alpha1.AuthSecretGitHubAppPrivateKeyField: pem.EncodeToMemory(&pem.Block{
+						Type:  "RSA PRIVATE KEY",
+						Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+					}),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &authSecret)).Should(Succeed())
+
+			repoDir := GinkgoT().TempDir()
+			repo, err := generateGitRepository(repoDir, fstest.MapFS{
+				"kustomization.yaml": {Data: []byte(`
+configMapGenerator:
+- name: github-app-auth
+  namespace: default
+`)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			head, err := repo.Head()
+			Expect(err).NotTo(HaveOccurred())
+
+			live := &kuberikiov1alpha1.Live{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "github-app-auth",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveSpec{
+					Path: LivePath,
+					Repository: kuberikiov1alpha1.Repository{
+						URL: fmt.Sprintf("file://%s", repoDir),
+						Auth: &kuberikiov1alpha1.RepositoryAuth{
+							SecretRef: corev1.LocalObjectReference{
+								Name: authSecret.Name,
+							},
+						},
+					},
+					Commit: head.Hash().String(),
+				},
+			}
+			Expect(k8sClient.Create(ctx, live)).Should(Succeed())
+
+			liveLookupKey := types.NamespacedName{Name: live.Name, Namespace: live.Namespace}
+			assertLiveReadyStatus(liveLookupKey, string(kuberikiov1alpha1.LivePhaseSucceeded), metav1.ConditionTrue, false)
+			Expect(k8sClient.Get(ctx, liveLookupKey, live)).Should(Succeed())
+			Expect(meta.FindStatusCondition(live.Status.Conditions, string(kuberikiov1alpha1.LiveConditionRepositoryAuth))).Should(SatisfyAll(
+				HaveField("Status", metav1.ConditionTrue),
+				HaveField("Reason", string(kuberikiov1alpha1.RepositoryAuthTypeGitHubApp)),
+			))
+		})
+	})
+
 	Context("Deleting a Live", func() {
 		It("Should clean up all deployed resources", func() {
 			By("Waiting for Live to reconcile")
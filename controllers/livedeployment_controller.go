@@ -18,14 +18,20 @@ package controllers
 
 import (
 	"context"
-	"path"
 	"time"
 
+	"github.com/go-git/go-git/v5/plumbing"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
 	"github.com/kuberik/kuberik/pkg/repository"
@@ -34,8 +40,13 @@ import (
 // LiveDeploymentReconciler reconciles a LiveDeployment object
 type LiveDeploymentReconciler struct {
 	client.Client
-	Scheme  *runtime.Scheme
-	RepoDir string
+	Scheme          *runtime.Scheme
+	Repositories    *repository.Cache
+	GitHubAppTokens kuberikiov1alpha1.GitHubAppTokenProvider
+	// PushEvents triggers reconciliation of the LiveDeployments when a push to the repository is received
+	PushEvents chan event.GenericEvent
+
+	polls pollRequests
 }
 
 //+kubebuilder:rbac:groups=kuberik.io,resources=livedeployments,verbs=get;list;watch;create;update;patch;delete
@@ -57,51 +68,211 @@ func (r *LiveDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	liveDeployment := &kuberikiov1alpha1.LiveDeployment{}
 	err := r.Client.Get(ctx, req.NamespacedName, liveDeployment)
 	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	poll := r.polls.take(req.NamespacedName) || liveDeployment.PollDue(time.Now())
+
+	liveDeployment.Status.Suspended = liveDeployment.Spec.Suspend
+	liveDeployment.Status.PinnedCommit = liveDeployment.Spec.PinnedCommit
+	liveDeployment.Status.RollbackTo = liveDeployment.Spec.RollbackTo
+
+	if !liveDeployment.Spec.Suspend && liveDeployment.Spec.RollbackTo != "" && liveDeployment.SucceededRevision(liveDeployment.Spec.RollbackTo) == nil {
+		liveDeployment.SetRollbackRevisionNotFound()
+		liveDeployment.Status.ObservedGeneration = liveDeployment.Generation
+		return ctrl.Result{}, r.Client.Status().Update(ctx, liveDeployment)
+	}
+	if !liveDeployment.Spec.Suspend && liveDeployment.Spec.Tag != nil {
+		if err := liveDeployment.Spec.Tag.Validate(); err != nil {
+			liveDeployment.SetInvalidTagSelector(err)
+			liveDeployment.Status.ObservedGeneration = liveDeployment.Generation
+			return ctrl.Result{}, r.Client.Status().Update(ctx, liveDeployment)
+		}
+	}
+
+	var live *kuberikiov1alpha1.Live
+	var requeueAfter time.Duration
+	if liveDeployment.Spec.Suspend {
+		live, err = r.getLive(ctx, liveDeployment)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		liveDeployment.RecordRevision(live)
+	} else {
+		live, err = r.deploy(ctx, liveDeployment, poll)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if liveDeployment.Spec.RollbackTo == "" && liveDeployment.Spec.PinnedCommit == "" {
+			requeueAfter = liveDeployment.UntilNextPoll(time.Now()) + time.Second
+		}
+		if deadline := liveDeployment.HealthDeadline(live); liveDeployment.AutoRollbackEnabled() && deadline != nil && !live.Reconciled() {
+			if untilDeadline := time.Until(*deadline); untilDeadline > 0 && (requeueAfter == 0 || untilDeadline < requeueAfter) {
+				requeueAfter = untilDeadline
+			}
+		}
+	}
+
+	liveDeployment.SetReadyFromLive(live)
+	liveDeployment.Status.ObservedGeneration = liveDeployment.Generation
+	if err := r.Client.Status().Update(ctx, liveDeployment); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	auth, err := liveDeployment.Spec.Template.Spec.GetAuthMethod(ctx, r.Client, liveDeployment.Namespace)
+	return ctrl.Result{
+		RequeueAfter: requeueAfter,
+	}, nil
+}
+
+// deploy updates the Live to deploy the resolved commit, rolling back the commit if it failed
+func (r *LiveDeploymentReconciler) deploy(ctx context.Context, liveDeployment *kuberikiov1alpha1.LiveDeployment, poll bool) (*kuberikiov1alpha1.Live, error) {
+	commitSHA, err := r.resolveCommit(ctx, liveDeployment, poll)
 	if err != nil {
-		return ctrl.Result{}, err
+		return nil, err
 	}
-	repo, err := repository.InitGitRepository(path.Join(r.RepoDir, liveDeployment.Spec.Template.Spec.Repository.URL), liveDeployment.Spec.Template.Spec.Repository.URL, auth)
+
+	if liveDeployment.AutoRollbackEnabled() {
+		if liveDeployment.Status.FailedCommit != commitSHA.String() {
+			liveDeployment.ClearFailedCommit()
+		} else if revision := liveDeployment.RollbackRevision(); revision != nil {
+			commitSHA = plumbing.NewHash(revision.Commit)
+		}
+	}
+
+	live, err := r.reconcileLive(ctx, liveDeployment, commitSHA)
 	if err != nil {
-		return ctrl.Result{}, err
+		return nil, err
 	}
+	liveDeployment.Status.Commit = live.Spec.Commit
+	liveDeployment.RecordRevision(live)
 
-	commitSHA, err := repo.FetchBranch(liveDeployment.Spec.Branch)
+	if !liveDeployment.AutoRollbackEnabled() || liveDeployment.Status.FailedCommit != "" {
+		return live, nil
+	}
+	reason := liveDeployment.AutoRollbackReason(live, time.Now())
+	if reason == "" {
+		return live, nil
+	}
+	log.FromContext(ctx).Info("rolling back failed commit", "commit", live.Spec.Commit, "reason", reason)
+	revision := liveDeployment.SetFailedCommit(live.Spec.Commit, reason)
+	if revision == nil {
+		return live, nil
+	}
+
+	live, err = r.reconcileLive(ctx, liveDeployment, plumbing.NewHash(revision.Commit))
 	if err != nil {
-		return ctrl.Result{}, err
+		return nil, err
+	}
+	liveDeployment.Status.Commit = live.Spec.Commit
+	liveDeployment.RecordRevision(live)
+	return live, nil
+}
+
+// resolveCommit returns the commit which should be deployed by the LiveDeployment.
+// Unless rolling back or the commit is pinned, the tip of the branch is fetched from the git repository when polling,
+// otherwise the commit resolved by the last poll is returned.
+func (r *LiveDeploymentReconciler) resolveCommit(ctx context.Context, liveDeployment *kuberikiov1alpha1.LiveDeployment, poll bool) (plumbing.Hash, error) {
+	if liveDeployment.Spec.RollbackTo != "" {
+		return plumbing.NewHash(liveDeployment.Spec.RollbackTo), nil
+	}
+	if liveDeployment.Spec.PinnedCommit != "" {
+		return plumbing.NewHash(liveDeployment.Spec.PinnedCommit), nil
+	}
+	if !poll {
+		// The failed commit is the tip of the branch which was rolled back instead of deployed
+		if liveDeployment.Status.FailedCommit != "" {
+			return plumbing.NewHash(liveDeployment.Status.FailedCommit), nil
+		}
+		if liveDeployment.Status.Commit != "" {
+			return plumbing.NewHash(liveDeployment.Status.Commit), nil
+		}
 	}
 
-	generatedLive := liveDeployment.CreateLiveForCommit(*commitSHA)
-	err = r.Client.Create(ctx, generatedLive)
+	repo, _, release, err := openRepository(ctx, r.Client, r.Repositories, liveDeployment.Spec.Template.Spec.Repository, liveDeployment.Namespace, r.GitHubAppTokens)
 	if err != nil {
-		if errors.IsAlreadyExists(err) {
-			existingLive := &kuberikiov1alpha1.Live{}
-			err = r.Client.Get(ctx, req.NamespacedName, existingLive)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
+		return plumbing.ZeroHash, err
+	}
+	defer release()
 
-			generatedLive.Spec.DeepCopyInto(&existingLive.Spec)
-			err = r.Client.Update(ctx, existingLive)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-		} else {
-			return ctrl.Result{}, err
+	if liveDeployment.Spec.Tag != nil {
+		tag, err := resolveTag(repo, liveDeployment.Spec.Tag)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commitSHA, err := repo.FetchTag(tag)
+		if err != nil {
+			return plumbing.ZeroHash, err
 		}
+		liveDeployment.Status.Tag = tag
+		liveDeployment.Status.LastPollTime = &metav1.Time{Time: time.Now()}
+		return *commitSHA, nil
 	}
 
-	return ctrl.Result{
-		RequeueAfter: time.Duration(liveDeployment.Spec.PollIntervalSeconds+1) * time.Second,
-	}, nil
+	commitSHA, err := repo.FetchBranch(liveDeployment.Spec.Branch)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	liveDeployment.Status.Tag = ""
+	liveDeployment.Status.LastPollTime = &metav1.Time{Time: time.Now()}
+	return *commitSHA, nil
+}
+
+// resolveTag returns the name of the tag selected by the valid selector
+func resolveTag(repo *repository.GitRepository, selector *kuberikiov1alpha1.TagSelector) (string, error) {
+	if selector.Name != "" {
+		return selector.Name, nil
+	}
+	tags, err := repo.ListTags()
+	if err != nil {
+		return "", err
+	}
+	return repository.SelectSemverTag(tags, selector.Semver)
+}
+
+// getLive returns the Live created by the LiveDeployment without updating it
+func (r *LiveDeploymentReconciler) getLive(ctx context.Context, liveDeployment *kuberikiov1alpha1.LiveDeployment) (*kuberikiov1alpha1.Live, error) {
+	live := &kuberikiov1alpha1.Live{}
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(liveDeployment), live)
+	if errors.IsNotFound(err) {
+		live.Name = liveDeployment.Name
+		live.Namespace = liveDeployment.Namespace
+		return live, nil
+	}
+	return live, err
+}
+
+// reconcileLive creates or updates the Live deploying the specified commit
+func (r *LiveDeploymentReconciler) reconcileLive(ctx context.Context, liveDeployment *kuberikiov1alpha1.LiveDeployment, commitSHA plumbing.Hash) (*kuberikiov1alpha1.Live, error) {
+	generatedLive := liveDeployment.CreateLiveForCommit(commitSHA)
+	err := r.Client.Create(ctx, generatedLive)
+	if err == nil {
+		return generatedLive, nil
+	}
+	if !errors.IsAlreadyExists(err) {
+		return nil, err
+	}
+
+	existingLive := &kuberikiov1alpha1.Live{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(generatedLive), existingLive); err != nil {
+		return nil, err
+	}
+	if equality.Semantic.DeepEqual(existingLive.Spec, generatedLive.Spec) {
+		return existingLive, nil
+	}
+
+	generatedLive.Spec.DeepCopyInto(&existingLive.Spec)
+	if err := r.Client.Update(ctx, existingLive); err != nil {
+		return nil, err
+	}
+	return existingLive, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LiveDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kuberikiov1alpha1.LiveDeployment{}).
-		Complete(r)
+	controller := ctrl.NewControllerManagedBy(mgr).
+		For(&kuberikiov1alpha1.LiveDeployment{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&kuberikiov1alpha1.Live{})
+	if r.PushEvents != nil {
+		controller = controller.Watches(&source.Channel{Source: r.PushEvents}, r.polls.handler())
+	}
+	return controller.Complete(r)
 }
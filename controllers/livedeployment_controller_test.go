@@ -97,6 +97,18 @@ var _ = Describe("LiveDeployment controller", func() {
 				)),
 			), "LiveDeployment %s should create Live %v", LiveDeploymentName, LiveName)
 
+			By("By reporting the deployed commit in the status")
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, liveDeploymentLookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(SatisfyAll(
+				HaveField("Status.Commit", LiveRepoCommit),
+				HaveField("Status.ObservedGeneration", createdLiveDeployment.Generation),
+				HaveField("Status.LastPollTime", Not(BeNil())),
+				WithTransform(func(ld *kuberikiov1alpha1.LiveDeployment) *metav1.Condition { return ld.GetReadyCondition() }, Not(BeNil())),
+			))
+
 			By("Creating a new commit on the branch")
 			fs := memfs.New()
 			inMemoryStorage := memory.NewStorage()
@@ -145,6 +157,292 @@ var _ = Describe("LiveDeployment controller", func() {
 		})
 	})
 
+	Context("When pinning and suspending a LiveDeployment", func() {
+		It("Should deploy the pinned commit and stop updating the Live while suspended", func() {
+			ctx := context.Background()
+			const (
+				pinnedCommit      = "918c48b83bd081e863dbe1b80f8998f058cd8294"
+				otherPinnedCommit = "af2d6a6954d532f8ffb47615169c8fdf9d383a1a"
+			)
+
+			By("By creating a LiveDeployment with a pinned commit")
+			liveDeployment := &kuberikiov1alpha1.LiveDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pinned-live-deployment",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+					Branch:       "master",
+					PinnedCommit: pinnedCommit,
+					Template: &kuberikiov1alpha1.LiveTemplate{
+						Spec: kuberikiov1alpha1.LiveSpec{
+							Path: ".",
+							Repository: kuberikiov1alpha1.Repository{
+								URL: fixtures.Basic().One().DotGit().Root(),
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, liveDeployment)).Should(Succeed())
+			liveLookupKey := types.NamespacedName{Name: liveDeployment.Name, Namespace: liveDeployment.Namespace}
+
+			Eventually(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, liveLookupKey, live)
+				return live, err
+			}, timeout, interval).Should(HaveField("Spec.Commit", pinnedCommit))
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, liveLookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(SatisfyAll(
+				HaveField("Status.Commit", pinnedCommit),
+				HaveField("Status.PinnedCommit", pinnedCommit),
+				HaveField("Status.Suspended", BeFalse()),
+			))
+
+			By("By suspending the LiveDeployment and changing the pinned commit")
+			Expect(k8sClient.Get(ctx, liveLookupKey, liveDeployment)).Should(Succeed())
+			liveDeployment.Spec.Suspend = true
+			liveDeployment.Spec.PinnedCommit = otherPinnedCommit
+			Expect(k8sClient.Update(ctx, liveDeployment)).Should(Succeed())
+
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, liveLookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(SatisfyAll(
+				HaveField("Status.Suspended", BeTrue()),
+				HaveField("Status.ObservedGeneration", liveDeployment.Generation),
+				HaveField("Status.Commit", pinnedCommit),
+			))
+			Consistently(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, liveLookupKey, live)
+				return live, err
+			}, time.Second*2, interval).Should(HaveField("Spec.Commit", pinnedCommit))
+
+			By("By resuming the LiveDeployment")
+			Expect(k8sClient.Get(ctx, liveLookupKey, liveDeployment)).Should(Succeed())
+			liveDeployment.Spec.Suspend = false
+			Expect(k8sClient.Update(ctx, liveDeployment)).Should(Succeed())
+
+			Eventually(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, liveLookupKey, live)
+				return live, err
+			}, timeout, interval).Should(HaveField("Spec.Commit", otherPinnedCommit))
+		})
+	})
+
+	Context("When rolling back a LiveDeployment", func() {
+		It("Should refuse to roll back to a commit which wasn't successfully deployed", func() {
+			ctx := context.Background()
+			const masterCommit = "6ecf0ef2c2dffb796033e5a02219af86ec6584e5"
+
+			By("By creating a LiveDeployment")
+			liveDeployment := &kuberikiov1alpha1.LiveDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rollback-live-deployment",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+					Branch: "master",
+					Template: &kuberikiov1alpha1.LiveTemplate{
+						Spec: kuberikiov1alpha1.LiveSpec{
+							Path: ".",
+							Repository: kuberikiov1alpha1.Repository{
+								URL: fixtures.Basic().One().DotGit().Root(),
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, liveDeployment)).Should(Succeed())
+			lookupKey := types.NamespacedName{Name: liveDeployment.Name, Namespace: liveDeployment.Namespace}
+
+			By("By recording the deployed commit in the history")
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, lookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(HaveField("Status.History", ContainElement(HaveField("Commit", masterCommit))))
+
+			By("By rolling back to a commit missing from the history")
+			Expect(k8sClient.Get(ctx, lookupKey, liveDeployment)).Should(Succeed())
+			liveDeployment.Spec.RollbackTo = "918c48b83bd081e863dbe1b80f8998f058cd8294"
+			Expect(k8sClient.Update(ctx, liveDeployment)).Should(Succeed())
+
+			Eventually(func() (*metav1.Condition, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, lookupKey, ld)
+				return ld.GetReadyCondition(), err
+			}, timeout, interval).Should(SatisfyAll(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", kuberikiov1alpha1.LiveDeploymentReasonRollbackRevisionNotFound),
+			))
+			Consistently(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, lookupKey, live)
+				return live, err
+			}, time.Second*2, interval).Should(HaveField("Spec.Commit", masterCommit))
+		})
+	})
+
+	Context("When deploying tags with a LiveDeployment", func() {
+		It("Should deploy the highest tag matching the semver constraint", func() {
+			ctx := context.Background()
+			const (
+				releaseCommit = "918c48b83bd081e863dbe1b80f8998f058cd8294"
+				patchCommit   = "af2d6a6954d532f8ffb47615169c8fdf9d383a1a"
+				masterCommit  = "6ecf0ef2c2dffb796033e5a02219af86ec6584e5"
+			)
+
+			By("By tagging the git repository")
+			repoURL := fixtures.Basic().One().DotGit().Root()
+			repo, err := git.PlainOpen(repoURL)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = repo.CreateTag("v1.2.0", plumbing.NewHash(releaseCommit), nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = repo.CreateTag("v2.0.0", plumbing.NewHash(masterCommit), nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("By creating a LiveDeployment selecting tags by semver")
+			liveDeployment := &kuberikiov1alpha1.LiveDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tag-live-deployment",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+					Tag: &kuberikiov1alpha1.TagSelector{
+						Semver: ">=1.0.0 <2.0.0",
+					},
+					PollIntervalSeconds: 1,
+					Template: &kuberikiov1alpha1.LiveTemplate{
+						Spec: kuberikiov1alpha1.LiveSpec{
+							Path: ".",
+							Repository: kuberikiov1alpha1.Repository{
+								URL: repoURL,
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, liveDeployment)).Should(Succeed())
+			lookupKey := types.NamespacedName{Name: liveDeployment.Name, Namespace: liveDeployment.Namespace}
+
+			Eventually(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, lookupKey, live)
+				return live, err
+			}, timeout, interval).Should(HaveField("Spec.Commit", releaseCommit))
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, lookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(SatisfyAll(
+				HaveField("Status.Tag", "v1.2.0"),
+				HaveField("Status.Commit", releaseCommit),
+			))
+
+			By("By releasing a new patch version")
+			_, err = repo.CreateTag("v1.2.1", plumbing.NewHash(patchCommit), &git.CreateTagOptions{
+				Tagger:  &object.Signature{Name: "kuberik", Email: "kuberik@example.com", When: time.Now()},
+				Message: "v1.2.1",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, lookupKey, live)
+				return live, err
+			}, timeout, interval).Should(HaveField("Spec.Commit", patchCommit))
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, lookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(HaveField("Status.Tag", "v1.2.1"))
+
+			By("By selecting the tag by name")
+			Expect(k8sClient.Get(ctx, lookupKey, liveDeployment)).Should(Succeed())
+			liveDeployment.Spec.Tag = &kuberikiov1alpha1.TagSelector{Name: "v2.0.0"}
+			Expect(k8sClient.Update(ctx, liveDeployment)).Should(Succeed())
+
+			Eventually(func() (*kuberikiov1alpha1.Live, error) {
+				live := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, lookupKey, live)
+				return live, err
+			}, timeout, interval).Should(HaveField("Spec.Commit", masterCommit))
+			Eventually(func() (*kuberikiov1alpha1.LiveDeployment, error) {
+				ld := &kuberikiov1alpha1.LiveDeployment{}
+				err := k8sClient.Get(ctx, lookupKey, ld)
+				return ld, err
+			}, timeout, interval).Should(HaveField("Status.Tag", "v2.0.0"))
+		})
+	})
+
+	Context("When creating a LiveDeployment referencing repo over SSH", func() {
+		It("Should create the Live resource", func() {
+			ctx := context.Background()
+
+			By("By starting a SSH git server")
+			identity, publicKey, err := generateSSHIdentity()
+			Expect(err).NotTo(HaveOccurred())
+			server, err := startSSHGitServer(publicKey)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(server.Close)
+
+			By("By creating a new LiveDeployment")
+			authSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ld-ssh-repo-auth",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					kuberikiov1alpha1.AuthSecretIdentityField:   identity,
+					kuberikiov1alpha1.AuthSecretKnownHostsField: []byte(server.KnownHosts()),
+				},
+			}
+			Expect(k8sClient.Create(ctx, &authSecret)).Should(Succeed())
+
+			liveDeployment := &kuberikiov1alpha1.LiveDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ssh-repo-live-deployment",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+					Branch: "master",
+					Template: &kuberikiov1alpha1.LiveTemplate{
+						Spec: kuberikiov1alpha1.LiveSpec{
+							Path: ".",
+							Repository: kuberikiov1alpha1.Repository{
+								URL: server.URL(fixtures.Basic().One().DotGit().Root()),
+								Auth: &kuberikiov1alpha1.RepositoryAuth{
+									SecretRef: corev1.LocalObjectReference{
+										Name: authSecret.Name,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, liveDeployment)).Should(Succeed())
+
+			By("By creating the Live resource")
+			Eventually(func() (*kuberikiov1alpha1.Live, error) {
+				createdLive := &kuberikiov1alpha1.Live{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: liveDeployment.Name, Namespace: liveDeployment.Namespace}, createdLive)
+				return createdLive, err
+			}, timeout, interval).Should(
+				HaveField("Spec.Commit", "6ecf0ef2c2dffb796033e5a02219af86ec6584e5"),
+				"LiveDeployment %s should create Live", liveDeployment.Name,
+			)
+		})
+	})
+
 	Context("When creating a LiveDeployment referencing private repo", func() {
 		It("Should create/update the Live resource", func() {
 			ctx := context.Background()
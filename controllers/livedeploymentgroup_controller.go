@@ -18,13 +18,17 @@ package controllers
 
 import (
 	"context"
-	"path"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
 	"github.com/kuberik/kuberik/pkg/repository"
@@ -33,8 +37,13 @@ import (
 // LiveDeploymentGroupReconciler reconciles a LiveDeploymentGroup object
 type LiveDeploymentGroupReconciler struct {
 	client.Client
-	Scheme  *runtime.Scheme
-	RepoDir string
+	Scheme          *runtime.Scheme
+	Repositories    *repository.Cache
+	GitHubAppTokens kuberikiov1alpha1.GitHubAppTokenProvider
+	// PushEvents triggers reconciliation of the LiveDeploymentGroups when a push to the repository is received
+	PushEvents chan event.GenericEvent
+
+	polls pollRequests
 }
 
 //+kubebuilder:rbac:groups=kuberik.io,resources=livedeploymentgroups,verbs=get;list;watch;create;update;patch;delete
@@ -56,40 +65,53 @@ func (r *LiveDeploymentGroupReconciler) Reconcile(ctx context.Context, req ctrl.
 	liveDeploymentGroup := &kuberikiov1alpha1.LiveDeploymentGroup{}
 	err := r.Client.Get(ctx, req.NamespacedName, liveDeploymentGroup)
 	if err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	auth, err := liveDeploymentGroup.Spec.Template.Spec.GetAuthMethod(ctx, r.Client, liveDeploymentGroup.Namespace)
-	if err != nil {
+	createdLiveDeployments := &kuberikiov1alpha1.LiveDeploymentList{}
+	if err := r.Client.List(ctx, createdLiveDeployments, &client.ListOptions{
+		Namespace:     liveDeploymentGroup.Namespace,
+		LabelSelector: liveDeploymentGroup.LiveDeploymentSelector(),
+	}); err != nil {
 		return ctrl.Result{}, err
 	}
-	repo, err := repository.InitGitRepository(path.Join(r.RepoDir, liveDeploymentGroup.Spec.Template.Spec.Repository.URL), liveDeploymentGroup.Spec.Template.Spec.Repository.URL, auth)
-	if err != nil {
-		return ctrl.Result{}, err
+
+	// Events of the created LiveDeployments only update their summary until the poll interval passes
+	if !r.polls.take(req.NamespacedName) && !liveDeploymentGroup.PollDue(time.Now()) {
+		liveDeploymentGroup.SetLiveDeployments(createdLiveDeployments.Items)
+		if err := r.Client.Status().Update(ctx, liveDeploymentGroup); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{
+			RequeueAfter: liveDeploymentGroup.UntilNextPoll(time.Now()) + time.Second,
+		}, nil
 	}
 
-	branches, err := repo.ListBranches(liveDeploymentGroup.Spec.BranchMatch)
+	branches, err := r.listBranches(ctx, liveDeploymentGroup)
 	if err != nil {
+		liveDeploymentGroup.SetListBranchesFailed(err)
+		liveDeploymentGroup.Status.ObservedGeneration = liveDeploymentGroup.Generation
+		if updateErr := r.Client.Status().Update(ctx, liveDeploymentGroup); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
 		return ctrl.Result{}, err
 	}
+	liveDeploymentGroup.Status.LastPollTime = &metav1.Time{Time: time.Now()}
 
-	createdLiveDeployments := &kuberikiov1alpha1.LiveDeploymentList{}
-	if err := r.Client.List(ctx, createdLiveDeployments, &client.ListOptions{
-		LabelSelector: liveDeploymentGroup.LiveDeploymentSelector(),
-	}); err != nil {
-		return ctrl.Result{}, nil
-	}
-
+	liveDeployments := []kuberikiov1alpha1.LiveDeployment{}
 branches:
 	for _, b := range branches {
 		for _, ld := range createdLiveDeployments.Items {
 			if ld.Spec.Branch == b {
+				liveDeployments = append(liveDeployments, ld)
 				continue branches
 			}
 		}
-		if err := r.Client.Create(ctx, liveDeploymentGroup.LiveDeploymentForBranch(b)); err != nil {
+		liveDeployment := liveDeploymentGroup.LiveDeploymentForBranch(b)
+		if err := r.Client.Create(ctx, liveDeployment); err != nil {
 			return ctrl.Result{}, err
 		}
+		liveDeployments = append(liveDeployments, *liveDeployment)
 	}
 
 liveDeployments:
@@ -104,14 +126,35 @@ liveDeployments:
 		}
 	}
 
+	liveDeploymentGroup.SetLiveDeployments(liveDeployments)
+	liveDeploymentGroup.Status.ObservedGeneration = liveDeploymentGroup.Generation
+	if err := r.Client.Status().Update(ctx, liveDeploymentGroup); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{
 		RequeueAfter: time.Duration(liveDeploymentGroup.Spec.PollIntervalSeconds+1) * time.Second,
 	}, nil
 }
 
+// listBranches lists the branches of the git repository matching the pattern of the LiveDeploymentGroup
+func (r *LiveDeploymentGroupReconciler) listBranches(ctx context.Context, liveDeploymentGroup *kuberikiov1alpha1.LiveDeploymentGroup) ([]string, error) {
+	repo, _, release, err := openRepository(ctx, r.Client, r.Repositories, liveDeploymentGroup.Spec.Template.Spec.Repository, liveDeploymentGroup.Namespace, r.GitHubAppTokens)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return repo.ListBranches(liveDeploymentGroup.Spec.BranchMatch)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LiveDeploymentGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&kuberikiov1alpha1.LiveDeploymentGroup{}).
-		Complete(r)
+	controller := ctrl.NewControllerManagedBy(mgr).
+		For(&kuberikiov1alpha1.LiveDeploymentGroup{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&kuberikiov1alpha1.LiveDeployment{})
+	if r.PushEvents != nil {
+		controller = controller.Watches(&source.Channel{Source: r.PushEvents}, r.polls.handler())
+	}
+	return controller.Complete(r)
 }
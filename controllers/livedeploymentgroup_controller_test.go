@@ -95,6 +95,28 @@ var _ = Describe("LiveDeploymentGroup controller", func() {
 				HaveLen(2),
 			), "LiveDeploymentGroup %s should create LiveDeployment for each matched branch", LiveDeploymentGroupName)
 
+			By("By reporting the matched branches in the status")
+			Eventually(func() (*kuberikiov1alpha1.LiveDeploymentGroup, error) {
+				ldg := &kuberikiov1alpha1.LiveDeploymentGroup{}
+				err := k8sClient.Get(ctx, liveDeploymentGroupLookupKey, ldg)
+				return ldg, err
+			}, timeout, interval).Should(SatisfyAll(
+				HaveField("Status.ObservedGeneration", createdLiveDeploymentGroup.Generation),
+				HaveField("Status.LastPollTime", Not(BeNil())),
+				HaveField("Status.Branches", SatisfyAll(
+					HaveLen(2),
+					ContainElement(SatisfyAll(
+						HaveField("Branch", "branch"),
+						HaveField("LiveDeployment", HavePrefix(LiveDeploymentGroupName+"-")),
+					)),
+					ContainElement(SatisfyAll(
+						HaveField("Branch", "master"),
+						HaveField("LiveDeployment", HavePrefix(LiveDeploymentGroupName+"-")),
+					)),
+				)),
+				WithTransform(func(ldg *kuberikiov1alpha1.LiveDeploymentGroup) *metav1.Condition { return ldg.GetReadyCondition() }, Not(BeNil())),
+			))
+
 			By("Deleting a branch")
 			fs := memfs.New()
 			inMemoryStorage := memory.NewStorage()
@@ -135,4 +157,37 @@ var _ = Describe("LiveDeploymentGroup controller", func() {
 			), "LiveDeploymentGroup %s should prune LiveDeployments for each deleted branch", LiveDeploymentGroupName)
 		})
 	})
+
+	Context("When creating a LiveDeploymentGroup referencing a missing repository", func() {
+		It("Should report the failure to list the branches", func() {
+			ctx := context.Background()
+			liveDeploymentGroup := &kuberikiov1alpha1.LiveDeploymentGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ldg-missing-repo",
+					Namespace: "default",
+				},
+				Spec: kuberikiov1alpha1.LiveDeploymentGroupSpec{
+					Template: &kuberikiov1alpha1.LiveTemplate{
+						Spec: kuberikiov1alpha1.LiveSpec{
+							Path: ".",
+							Repository: kuberikiov1alpha1.Repository{
+								URL: "file:///non/existing/repository",
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, liveDeploymentGroup)).Should(Succeed())
+
+			Eventually(func() (*metav1.Condition, error) {
+				ldg := &kuberikiov1alpha1.LiveDeploymentGroup{}
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(liveDeploymentGroup), ldg)
+				return ldg.GetReadyCondition(), err
+			}, timeout, interval).Should(SatisfyAll(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", kuberikiov1alpha1.LiveDeploymentGroupReasonListBranchesFailed),
+			))
+		})
+	})
 })
@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// pollRequests records the objects which need to poll their git repository on the next reconciliation
+// regardless of their poll interval, e.g. after a push to the repository was received
+type pollRequests struct {
+	mu        sync.Mutex
+	requested map[types.NamespacedName]bool
+}
+
+// handler enqueues the objects of the generic events and requests them to poll their git repository
+func (p *pollRequests) handler() handler.EventHandler {
+	return handler.Funcs{
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			key := client.ObjectKeyFromObject(e.Object)
+			p.mu.Lock()
+			if p.requested == nil {
+				p.requested = map[types.NamespacedName]bool{}
+			}
+			p.requested[key] = true
+			p.mu.Unlock()
+			q.Add(reconcile.Request{NamespacedName: key})
+		},
+	}
+}
+
+// take returns whether the object was requested to poll its git repository and clears the request
+func (p *pollRequests) take(key types.NamespacedName) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	requested := p.requested[key]
+	delete(p.requested, key)
+	return requested
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"github.com/kuberik/kuberik/pkg/repository"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func repositoryTLSConfig(repo kuberikiov1alpha1.Repository) *repository.TLSConfig {
+	if repo.TLSConfig == nil {
+		return nil
+	}
+	return &repository.TLSConfig{
+		CABundle:        repo.TLSConfig.CABundle,
+		InsecureSkipTLS: repo.TLSConfig.InsecureSkipTLSVerify,
+	}
+}
+
+// openRepository opens the git repository from the shared cache, along with the credentials type used to access it.
+// The repository is locked until the returned release function is called.
+func openRepository(ctx context.Context, c client.Client, cache *repository.Cache, repo kuberikiov1alpha1.Repository, namespace string, githubApp kuberikiov1alpha1.GitHubAppTokenProvider) (*repository.GitRepository, kuberikiov1alpha1.RepositoryAuthType, func(), error) {
+	auth, authType, err := repo.GetAuthMethod(ctx, c, namespace, githubApp)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to get auth method: %v", err)
+	}
+	gitRepo, release, err := cache.Open(repo.URL, repo.CredentialsIdentity(namespace), auth, repositoryTLSConfig(repo))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to init git repository: %v", err)
+	}
+	return gitRepo, authType, release, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"github.com/kuberik/kuberik/pkg/repository"
+)
+
+// RepositoryGarbageCollector periodically removes the repositories and the checked out commits
+// which aren't used by any Live, LiveDeployment or LiveDeploymentGroup from the repository cache.
+type RepositoryGarbageCollector struct {
+	client.Client
+	Repositories *repository.Cache
+	// Interval between the garbage collections
+	Interval time.Duration
+	// KeepRecentCommits is the number of most recently used commits kept for each repository even if they aren't used
+	KeepRecentCommits int
+}
+
+// Start collects the garbage once on start, e.g. the repositories left over from before a restart,
+// and then periodically until the context is done
+func (g *RepositoryGarbageCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+	for {
+		if err := g.GarbageCollect(ctx); err != nil {
+			log.FromContext(ctx).Error(err, "failed to garbage collect repository cache")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection makes sure the garbage is collected only alongside the controllers filling the repository cache
+func (g *RepositoryGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// GarbageCollect removes the repositories and the commits which aren't used from the repository cache
+func (g *RepositoryGarbageCollector) GarbageCollect(ctx context.Context) error {
+	references, err := g.references(ctx)
+	if err != nil {
+		return err
+	}
+	gc, err := g.Repositories.GarbageCollect(references, g.KeepRecentCommits)
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).V(1).Info("garbage collected repository cache",
+		"removedRepositories", gc.RemovedRepositories,
+		"removedCommits", gc.RemovedCommits,
+		"prunedObjects", gc.PrunedObjects,
+		"commits", gc.Usage.Commits,
+		"bytes", gc.Usage.ObjectBytes+gc.Usage.CommitBytes,
+	)
+	return nil
+}
+
+// references lists the repositories and the commits used by the Lives, LiveDeployments and LiveDeploymentGroups
+func (g *RepositoryGarbageCollector) references(ctx context.Context) ([]repository.Reference, error) {
+	var references []repository.Reference
+	reference := func(repo kuberikiov1alpha1.Repository, namespace, commit string) repository.Reference {
+		return repository.Reference{
+			URL:         repo.URL,
+			Credentials: repo.CredentialsIdentity(namespace),
+			Commit:      commit,
+		}
+	}
+
+	lives := &kuberikiov1alpha1.LiveList{}
+	if err := g.Client.List(ctx, lives); err != nil {
+		return nil, err
+	}
+	for _, live := range lives.Items {
+		references = append(references, reference(live.Spec.Repository, live.Namespace, live.Spec.Commit))
+		// The last applied commit is needed to roll back the apply
+		if live.Status.LastAppliedCommit != "" {
+			references = append(references, reference(live.Spec.Repository, live.Namespace, live.Status.LastAppliedCommit))
+		}
+	}
+
+	liveDeployments := &kuberikiov1alpha1.LiveDeploymentList{}
+	if err := g.Client.List(ctx, liveDeployments); err != nil {
+		return nil, err
+	}
+	for _, liveDeployment := range liveDeployments.Items {
+		references = append(references, reference(liveDeployment.Spec.Template.Spec.Repository, liveDeployment.Namespace, ""))
+	}
+
+	liveDeploymentGroups := &kuberikiov1alpha1.LiveDeploymentGroupList{}
+	if err := g.Client.List(ctx, liveDeploymentGroups); err != nil {
+		return nil, err
+	}
+	for _, liveDeploymentGroup := range liveDeploymentGroups.Items {
+		references = append(references, reference(liveDeploymentGroup.Spec.Template.Spec.Repository, liveDeploymentGroup.Namespace, ""))
+	}
+	return references, nil
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"net/http/httptest"
 	"path/filepath"
 	"testing"
 
@@ -35,6 +36,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"github.com/kuberik/kuberik/pkg/githubapp"
+	livepkg "github.com/kuberik/kuberik/pkg/live"
+	"github.com/kuberik/kuberik/pkg/repository"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -48,6 +52,7 @@ var (
 	testEnv   *envtest.Environment
 	ctx       context.Context
 	cancel    context.CancelFunc
+	githubAPI *httptest.Server
 )
 
 func TestAPIs(t *testing.T) {
@@ -89,28 +94,39 @@ var _ = BeforeSuite(func() {
 	})
 	Expect(err).ToNot(HaveOccurred())
 
+	githubAPI = newFakeGitHubAPI()
+	githubAppTokens := githubapp.NewTokenCache(&githubapp.HTTPTokenExchanger{APIURL: githubAPI.URL})
+	repositories := repository.NewCache(GinkgoT().TempDir())
+
 	err = (&LiveDeploymentReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		RepoDir: GinkgoT().TempDir(),
+		Client:          k8sManager.GetClient(),
+		Scheme:          k8sManager.GetScheme(),
+		Repositories:    repositories,
+		GitHubAppTokens: githubAppTokens,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
 	err = (&LiveDeploymentGroupReconciler{
-		Client:  k8sManager.GetClient(),
-		Scheme:  k8sManager.GetScheme(),
-		RepoDir: GinkgoT().TempDir(),
+		Client:          k8sManager.GetClient(),
+		Scheme:          k8sManager.GetScheme(),
+		Repositories:    repositories,
+		GitHubAppTokens: githubAppTokens,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	liveExecutor := livepkg.NewExecutor(4)
+	Expect(k8sManager.Add(liveExecutor)).To(Succeed())
+
 	err = (&LiveReconciler{
-		Client:          k8sManager.GetClient(),
-		Scheme:          k8sManager.GetScheme(),
-		RepoDir:         GinkgoT().TempDir(),
-		Config:          cfg,
-		ApplyResults:    make(map[types.NamespacedName]<-chan error),
-		DeleteResults:   make(map[types.NamespacedName]<-chan error),
-		KptClientEvents: make(chan event.GenericEvent, 1000),
+		Client:                  k8sManager.GetClient(),
+		Scheme:                  k8sManager.GetScheme(),
+		Repositories:            repositories,
+		Config:                  cfg,
+		Executor:                liveExecutor,
+		HealthWatches:           make(map[types.NamespacedName]*livepkg.HealthWatch),
+		KptClientEvents:         make(chan event.GenericEvent, 1000),
+		GitHubAppTokens:         githubAppTokens,
+		MaxConcurrentReconciles: 2,
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -124,6 +140,7 @@ var _ = BeforeSuite(func() {
 
 var _ = AfterSuite(func() {
 	cancel()
+	githubAPI.Close()
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
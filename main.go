@@ -18,7 +18,10 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -35,6 +38,10 @@ import (
 
 	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
 	"github.com/kuberik/kuberik/controllers"
+	"github.com/kuberik/kuberik/pkg/githubapp"
+	livepkg "github.com/kuberik/kuberik/pkg/live"
+	"github.com/kuberik/kuberik/pkg/receiver"
+	"github.com/kuberik/kuberik/pkg/repository"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -61,8 +68,29 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var githubAPIURL string
+	var pushWebhookAddr string
+	var pushWebhookSecret string
+	var maxConcurrentApplies int
+	var liveConcurrentReconciles int
+	var repoCacheDir string
+	var repoGCInterval time.Duration
+	var repoKeepRecentCommits int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&githubAPIURL, "github-api-url", githubapp.DefaultAPIURL, "The URL of the GitHub API used to mint GitHub App installation tokens.")
+	flag.StringVar(&pushWebhookAddr, "push-webhook-bind-address", ":8082", "The address the git push webhook receiver binds to.")
+	flag.StringVar(&pushWebhookSecret, "push-webhook-secret", "",
+		"The namespace/name of the Secret holding the token used to verify git push webhooks. "+
+			"The push webhook receiver is disabled if not set.")
+	flag.IntVar(&maxConcurrentApplies, "max-concurrent-applies", 4, "The maximum number of Live applies and destroys running at the same time.")
+	flag.IntVar(&liveConcurrentReconciles, "live-concurrent-reconciles", 1, "The maximum number of Lives reconciled at the same time.")
+	flag.StringVar(&repoCacheDir, "repo-cache-dir", "",
+		"The directory in which the git repositories are cached, e.g. the mount path of a persistent volume "+
+			"to reuse the cached repositories after restarts. A temporary directory is used if not set.")
+	flag.DurationVar(&repoGCInterval, "repo-gc-interval", 10*time.Minute, "The interval between garbage collections of the git repository cache.")
+	flag.IntVar(&repoKeepRecentCommits, "repo-keep-recent-commits", 5,
+		"The number of most recently used checked out commits kept for each git repository even if no Live uses them.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -87,25 +115,54 @@ func main() {
 		os.Exit(1)
 	}
 
-	liveRepoDir, _ := os.MkdirTemp("", "")
+	githubAppTokens := githubapp.NewTokenCache(&githubapp.HTTPTokenExchanger{APIURL: githubAPIURL})
+	liveDeploymentPushEvents := make(chan event.GenericEvent, 1000)
+	liveDeploymentGroupPushEvents := make(chan event.GenericEvent, 1000)
+
+	liveExecutor := livepkg.NewExecutor(maxConcurrentApplies)
+	if err := mgr.Add(liveExecutor); err != nil {
+		setupLog.Error(err, "unable to add apply executor")
+		os.Exit(1)
+	}
+
+	repoCacheDir, err = prepareRepoCacheDir(repoCacheDir)
+	if err != nil {
+		setupLog.Error(err, "invalid repository cache directory", "dir", repoCacheDir)
+		os.Exit(1)
+	}
+	setupLog.Info("caching git repositories", "dir", repoCacheDir)
+	repositories := repository.NewCache(repoCacheDir)
+	if err := mgr.Add(&controllers.RepositoryGarbageCollector{
+		Client:            mgr.GetClient(),
+		Repositories:      repositories,
+		Interval:          repoGCInterval,
+		KeepRecentCommits: repoKeepRecentCommits,
+	}); err != nil {
+		setupLog.Error(err, "unable to add repository garbage collector")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.LiveReconciler{
-		Client:          mgr.GetClient(),
-		Scheme:          mgr.GetScheme(),
-		Config:          mgr.GetConfig(),
-		RepoDir:         liveRepoDir,
-		ApplyResults:    make(map[types.NamespacedName]<-chan error),
-		DeleteResults:   make(map[types.NamespacedName]<-chan error),
-		KptClientEvents: make(chan event.GenericEvent, 1000),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Config:                  mgr.GetConfig(),
+		Repositories:            repositories,
+		Executor:                liveExecutor,
+		HealthWatches:           make(map[types.NamespacedName]*livepkg.HealthWatch),
+		KptClientEvents:         make(chan event.GenericEvent, 1000),
+		GitHubAppTokens:         githubAppTokens,
+		MaxConcurrentReconciles: liveConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Live")
 		os.Exit(1)
 	}
 
-	liveDeploymentRepoDir, _ := os.MkdirTemp("", "")
 	if err = (&controllers.LiveDeploymentReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		RepoDir: liveDeploymentRepoDir,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Repositories:    repositories,
+		GitHubAppTokens: githubAppTokens,
+		PushEvents:      liveDeploymentPushEvents,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LiveDeployment")
 		os.Exit(1)
@@ -117,17 +174,36 @@ func main() {
 		}
 	}
 
-	liveDeploymentGroupRepoDir, _ := os.MkdirTemp("", "")
 	if err = (&controllers.LiveDeploymentGroupReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		RepoDir: liveDeploymentGroupRepoDir,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Repositories:    repositories,
+		GitHubAppTokens: githubAppTokens,
+		PushEvents:      liveDeploymentGroupPushEvents,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "LiveDeploymentGroup")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder
 
+	if pushWebhookSecret != "" {
+		secretRef := strings.SplitN(pushWebhookSecret, "/", 2)
+		if len(secretRef) != 2 {
+			setupLog.Error(fmt.Errorf("expected namespace/name, got %q", pushWebhookSecret), "invalid push webhook secret")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&receiver.Receiver{
+			Client:                    mgr.GetClient(),
+			BindAddress:               pushWebhookAddr,
+			SecretRef:                 types.NamespacedName{Namespace: secretRef[0], Name: secretRef[1]},
+			LiveDeploymentEvents:      liveDeploymentPushEvents,
+			LiveDeploymentGroupEvents: liveDeploymentGroupPushEvents,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up push webhook receiver")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -143,3 +219,20 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// prepareRepoCacheDir makes sure the repository cache directory exists and is writable.
+// A temporary directory is created if the directory isn't set.
+func prepareRepoCacheDir(dir string) (string, error) {
+	if dir == "" {
+		return os.MkdirTemp("", "kuberik-repositories-")
+	}
+	if err := os.MkdirAll(dir, 0775); err != nil {
+		return dir, err
+	}
+	f, err := os.CreateTemp(dir, ".write-check-")
+	if err != nil {
+		return dir, fmt.Errorf("directory is not writable: %v", err)
+	}
+	f.Close()
+	return dir, os.Remove(f.Name())
+}
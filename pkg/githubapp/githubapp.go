@@ -0,0 +1,209 @@
+package githubapp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// DefaultAPIURL is the URL of the public GitHub API
+	DefaultAPIURL = "https://api.github.com"
+
+	// jwtLifetime is the lifetime of the JWTs used to authenticate as a GitHub App. GitHub allows at most 10 minutes.
+	jwtLifetime = 9 * time.Minute
+	// jwtClockSkew is subtracted from the JWT issue time to allow for clock drift between the controller and GitHub.
+	jwtClockSkew = time.Minute
+	// defaultRefreshBefore is the time before the expiry at which installation tokens are refreshed.
+	defaultRefreshBefore = 5 * time.Minute
+	// defaultExchangeTimeout limits the time of the requests exchanging the tokens, so that an unresponsive GitHub API
+	// doesn't block the reconcilers waiting for the tokens indefinitely.
+	defaultExchangeTimeout = 30 * time.Second
+)
+
+// InstallationToken is a short-lived token which grants access to the repositories of a GitHub App installation
+type InstallationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TokenExchanger exchanges a JWT signed by a GitHub App for an installation token
+type TokenExchanger interface {
+	Exchange(ctx context.Context, installationID int64, jwt string) (*InstallationToken, error)
+}
+
+var _ TokenExchanger = &HTTPTokenExchanger{}
+
+// HTTPTokenExchanger exchanges tokens using the GitHub REST API
+type HTTPTokenExchanger struct {
+	// APIURL is the base URL of the GitHub API. Defaults to DefaultAPIURL.
+	APIURL string
+	// Client is the HTTP client used to make the requests. Defaults to a client timing out after 30 seconds.
+	Client *http.Client
+}
+
+// Exchange implements TokenExchanger
+func (e *HTTPTokenExchanger) Exchange(ctx context.Context, installationID int64, jwt string) (*InstallationToken, error) {
+	apiURL := e.APIURL
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultExchangeTimeout}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(apiURL, "/"), installationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create installation token: unexpected status %s", resp.Status)
+	}
+
+	token := &InstallationToken{}
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return nil, fmt.Errorf("failed to decode installation token: %v", err)
+	}
+	if token.Token == "" {
+		return nil, fmt.Errorf("installation token missing in the response")
+	}
+	return token, nil
+}
+
+type tokenKey struct {
+	appID          int64
+	installationID int64
+	privateKey     [sha256.Size]byte
+}
+
+// TokenCache mints installation tokens for GitHub Apps and caches them until they are about to expire
+type TokenCache struct {
+	exchanger TokenExchanger
+	// RefreshBefore is the time before the expiry at which the tokens are refreshed
+	RefreshBefore time.Duration
+
+	mu     sync.Mutex
+	tokens map[tokenKey]*tokenEntry
+	now    func() time.Time
+}
+
+// tokenEntry holds the token of a single installation. Its lock is held while the token is exchanged,
+// so that only the requests for the same installation wait for the exchange.
+type tokenEntry struct {
+	mu    sync.Mutex
+	token *InstallationToken
+}
+
+func NewTokenCache(exchanger TokenExchanger) *TokenCache {
+	return &TokenCache{
+		exchanger:     exchanger,
+		RefreshBefore: defaultRefreshBefore,
+		tokens:        make(map[tokenKey]*tokenEntry),
+		now:           time.Now,
+	}
+}
+
+// InstallationToken returns a valid installation token for the specified GitHub App installation.
+// Private key needs to be PEM encoded.
+func (c *TokenCache) InstallationToken(ctx context.Context, appID, installationID int64, privateKey []byte) (string, error) {
+	key := tokenKey{
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     sha256.Sum256(privateKey),
+	}
+
+	entry := c.entry(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	now := c.now()
+	if entry.token != nil && entry.token.ExpiresAt.Sub(now) > c.RefreshBefore {
+		return entry.token.Token, nil
+	}
+
+	jwt, err := newJWT(appID, privateKey, now)
+	if err != nil {
+		return "", err
+	}
+	token, err := c.exchanger.Exchange(ctx, installationID, jwt)
+	if err != nil {
+		return "", err
+	}
+	entry.token = token
+	return token.Token, nil
+}
+
+func (c *TokenCache) entry(key tokenKey) *tokenEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.tokens[key]
+	if !ok {
+		entry = &tokenEntry{}
+		c.tokens[key] = entry
+	}
+	return entry
+}
+
+// newJWT creates a JWT signed with the private key of a GitHub App which can be used to authenticate as the app.
+func newJWT(appID int64, privateKey []byte, now time.Time) (string, error) {
+	parsedKey, err := ssh.ParseRawPrivateKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key: %v", err)
+	}
+	rsaKey, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("private key needs to be a RSA key")
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": fmt.Sprintf("%d", appID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var jwt bytes.Buffer
+	jwt.WriteString(base64.RawURLEncoding.EncodeToString(header))
+	jwt.WriteString(".")
+	jwt.WriteString(base64.RawURLEncoding.EncodeToString(claims))
+
+	digest := sha256.Sum256(jwt.Bytes())
+	signature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	jwt.WriteString(".")
+	jwt.WriteString(base64.RawURLEncoding.EncodeToString(signature))
+	return jwt.String(), nil
+}
@@ -0,0 +1,195 @@
+package githubapp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func generatePrivateKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NilError(t, err, "failed to generate private key")
+	return key, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+}
+
+type fakeGitHubAPI struct {
+	*httptest.Server
+	publicKey *rsa.PublicKey
+	appID     string
+	exchanges int
+	expiresIn time.Duration
+}
+
+func newFakeGitHubAPI(t *testing.T, appID int64, publicKey *rsa.PublicKey) *fakeGitHubAPI {
+	api := &fakeGitHubAPI{
+		publicKey: publicKey,
+		appID:     fmt.Sprintf("%d", appID),
+		expiresIn: time.Hour,
+	}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var installationID int64
+		if _, err := fmt.Sscanf(r.URL.Path, "/app/installations/%d/access_tokens", &installationID); err != nil || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := api.verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		api.exchanges++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(InstallationToken{
+			Token:     fmt.Sprintf("token-%d-%d", installationID, api.exchanges),
+			ExpiresAt: time.Now().Add(api.expiresIn),
+		})
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (api *fakeGitHubAPI) verifyJWT(jwt string) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed jwt")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(api.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		return err
+	}
+
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	claims := struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}{}
+	if err := json.Unmarshal(rawClaims, &claims); err != nil {
+		return err
+	}
+	if claims.Issuer != api.appID {
+		return fmt.Errorf("unexpected issuer %s", claims.Issuer)
+	}
+	if claims.ExpiresAt-claims.IssuedAt > int64((10 * time.Minute).Seconds()) {
+		return fmt.Errorf("jwt lifetime too long")
+	}
+	return nil
+}
+
+func TestTokenCache(t *testing.T) {
+	const appID = 1234
+	key, keyPEM := generatePrivateKey(t)
+	api := newFakeGitHubAPI(t, appID, &key.PublicKey)
+
+	cache := NewTokenCache(&HTTPTokenExchanger{APIURL: api.URL})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	token, err := cache.InstallationToken(context.TODO(), appID, 42, keyPEM)
+	assert.NilError(t, err, "failed to get installation token")
+	assert.Equal(t, token, "token-42-1")
+
+	// Cached token is reused
+	token, err = cache.InstallationToken(context.TODO(), appID, 42, keyPEM)
+	assert.NilError(t, err, "failed to get installation token")
+	assert.Equal(t, token, "token-42-1")
+	assert.Equal(t, api.exchanges, 1)
+
+	// Each installation gets its own token
+	token, err = cache.InstallationToken(context.TODO(), appID, 43, keyPEM)
+	assert.NilError(t, err, "failed to get installation token")
+	assert.Equal(t, token, "token-43-2")
+
+	// Token is refreshed before it expires
+	now = now.Add(time.Hour - cache.RefreshBefore + time.Second)
+	token, err = cache.InstallationToken(context.TODO(), appID, 42, keyPEM)
+	assert.NilError(t, err, "failed to get installation token")
+	assert.Equal(t, token, "token-42-3")
+}
+
+func TestTokenCacheErrors(t *testing.T) {
+	const appID = 1234
+	key, keyPEM := generatePrivateKey(t)
+	api := newFakeGitHubAPI(t, appID, &key.PublicKey)
+	cache := NewTokenCache(&HTTPTokenExchanger{APIURL: api.URL})
+
+	_, otherKeyPEM := generatePrivateKey(t)
+	_, err := cache.InstallationToken(context.TODO(), appID, 42, otherKeyPEM)
+	assert.ErrorContains(t, err, "401")
+
+	_, err = cache.InstallationToken(context.TODO(), 4321, 42, keyPEM)
+	assert.ErrorContains(t, err, "401")
+
+	_, err = cache.InstallationToken(context.TODO(), appID, 42, []byte("foo"))
+	assert.ErrorContains(t, err, "failed to parse private key")
+	assert.Equal(t, api.exchanges, 0)
+}
+
+// blockingExchanger blocks the exchanges of the installation until it is unblocked
+type blockingExchanger struct {
+	installationID int64
+	started        chan struct{}
+	unblock        chan struct{}
+}
+
+func (e *blockingExchanger) Exchange(ctx context.Context, installationID int64, jwt string) (*InstallationToken, error) {
+	if installationID == e.installationID {
+		close(e.started)
+		<-e.unblock
+	}
+	return &InstallationToken{Token: fmt.Sprintf("token-%d", installationID), ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func TestTokenCacheConcurrentExchanges(t *testing.T) {
+	const appID = 1234
+	_, keyPEM := generatePrivateKey(t)
+	exchanger := &blockingExchanger{installationID: 42, started: make(chan struct{}), unblock: make(chan struct{})}
+	cache := NewTokenCache(exchanger)
+
+	blocked := make(chan string)
+	go func() {
+		token, _ := cache.InstallationToken(context.TODO(), appID, 42, keyPEM)
+		blocked <- token
+	}()
+	<-exchanger.started
+
+	// Exchanges of other installations don't wait for the blocked one
+	done := make(chan string)
+	go func() {
+		token, _ := cache.InstallationToken(context.TODO(), appID, 43, keyPEM)
+		done <- token
+	}()
+	select {
+	case token := <-done:
+		assert.Equal(t, token, "token-43")
+	case <-time.After(10 * time.Second):
+		t.Fatal("exchange blocked by the exchange of another installation")
+	}
+
+	close(exchanger.unblock)
+	assert.Equal(t, <-blocked, "token-42")
+}
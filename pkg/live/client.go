@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"time"
 
 	kptfilev1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
@@ -29,13 +28,16 @@ import (
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubernetes/cmd/kubeadm/app/util/kubeconfig"
 	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
 	"sigs.k8s.io/cli-utils/pkg/object"
 
 	// statscommon "sigs.k8s.io/cli-utils/pkg/print/common"
 
-	"sigs.k8s.io/cli-utils/pkg/printers"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -194,20 +196,55 @@ func kyamlNodeToUnstructured(n *yaml.RNode) (*unstructured.Unstructured, error)
 }
 
 type ApplyOptions struct {
-	Timeout      time.Duration
+	// ReconcileTimeout is the maximum duration of waiting for the applied resources to become ready
+	ReconcileTimeout time.Duration
+	// PruneTimeout is the maximum duration of waiting for the pruned resources to be deleted
 	PruneTimeout time.Duration
+	// PrunePropagationPolicy defaults to background propagation
+	PrunePropagationPolicy metav1.DeletionPropagation
+	// InventoryPolicy defaults to inventory.PolicyAdoptIfNoInventory
+	InventoryPolicy *inventory.Policy
+	// NoWait skips waiting for the applied resources to become ready, their statuses aren't reported.
+	// It's ignored if the resources are applied in sync waves.
+	NoWait bool
+	// OnWaveProgress is called with the progress of the sync waves whenever it changes
+	OnWaveProgress func([]WaveProgress)
 }
 
-func (c *KptClient) Apply(resMap resmap.ResMap, options ApplyOptions) error {
-	invClientFactory := util.NewFactory(c.resourceGroupClientGetter)
-	invClient, err := inventory.NewClient(invClientFactory, live.WrapInventoryObj, live.InvToUnstructuredFunc, inventory.StatusPolicyAll, live.ResourceGroupGVK)
+// Apply applies the resources and returns the outcome for each of the applied and pruned objects
+func (c *KptClient) Apply(resMap resmap.ResMap, options ApplyOptions) ([]ObjectResult, error) {
+	applyObjects, err := newKptApplyObjects(resMap)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	waves, err := orderSyncWaves(applyObjects.objects)
+	if err != nil {
+		return nil, err
+	}
+	if waves != nil {
+		// Each wave has to reconcile before the next one is applied
+		options.NoWait = false
 	}
 
-	applyObjects, err := newKptApplyObjects(resMap)
+	ch, err := c.run(applyObjects, options, common.DryRunNone)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if waves != nil && options.OnWaveProgress != nil {
+		ch = waves.track(ch, options.OnWaveProgress)
+	}
+	return collectResults(ch, applyObjects.objects)
+}
+
+func (c *KptClient) inventoryClient() (inventory.Client, error) {
+	return inventory.NewClient(util.NewFactory(c.resourceGroupClientGetter), live.WrapInventoryObj, live.InvToUnstructuredFunc, inventory.StatusPolicyAll, live.ResourceGroupGVK)
+}
+
+// run starts applying the objects and returns the channel of the applier events
+func (c *KptClient) run(applyObjects *kptApplyObjects, options ApplyOptions, dryRunStrategy common.DryRunStrategy) (<-chan event.Event, error) {
+	invClient, err := c.inventoryClient()
+	if err != nil {
+		return nil, err
 	}
 
 	invInfo, err := live.ToInventoryInfo(kptfilev1.Inventory{
@@ -216,41 +253,80 @@ func (c *KptClient) Apply(resMap resmap.ResMap, options ApplyOptions) error {
 		InventoryID: applyObjects.resourceGroup.GetLabels()[common.InventoryLabel],
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	factory := util.NewFactory(c.resourceClientGetter)
-	applier, err := apply.NewApplierBuilder().
+	builder := apply.NewApplierBuilder().
 		WithFactory(factory).
-		WithInventoryClient(invClient).
-		Build()
+		WithInventoryClient(invClient)
+	reconcileTimeout := options.ReconcileTimeout
+	if options.NoWait {
+		// The applier always waits for the applied objects, without a timeout the wait ends
+		// only once they're reconciled, which the watcher reports as soon as they're observed
+		statusWatcher, err := status.NewStatusWatcher(factory)
+		if err != nil {
+			return nil, err
+		}
+		builder = builder.WithStatusWatcher(&noWaitStatusWatcher{
+			StatusWatcher: statusWatcher,
+			applied:       object.UnstructuredSetToObjMetadataSet(applyObjects.objects),
+		})
+		reconcileTimeout = 0
+	}
+	applier, err := builder.Build()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	dryRunStrategy := common.DryRunNone
-	ch, err := applier.Run(c.ctx, invInfo, applyObjects.objects, apply.ApplierOptions{
+	prunePropagationPolicy := options.PrunePropagationPolicy
+	if prunePropagationPolicy == "" {
+		prunePropagationPolicy = metav1.DeletePropagationBackground
+	}
+	inventoryPolicy := inventory.PolicyAdoptIfNoInventory
+	if options.InventoryPolicy != nil {
+		inventoryPolicy = *options.InventoryPolicy
+	}
+
+	return applier.Run(c.ctx, invInfo, applyObjects.objects, apply.ApplierOptions{
 		// TODO: Use server-side
 		ServerSideOptions: common.ServerSideOptions{
 			ServerSideApply: true,
 			FieldManager:    fmt.Sprintf("rg/%s/%s", applyObjects.resourceGroup.GetNamespace(), applyObjects.resourceGroup.GetName()),
 		},
-		ReconcileTimeout:       options.Timeout,
-		EmitStatusEvents:       true, // We are always waiting for reconcile.
+		ReconcileTimeout: reconcileTimeout,
+		// Statuses of the objects which weren't waited for aren't reported
+		EmitStatusEvents:       !options.NoWait,
 		DryRunStrategy:         dryRunStrategy,
-		PrunePropagationPolicy: metav1.DeletePropagationBackground,
+		PrunePropagationPolicy: prunePropagationPolicy,
 		PruneTimeout:           options.PruneTimeout,
-		InventoryPolicy:        inventory.PolicyAdoptIfNoInventory,
+		InventoryPolicy:        inventoryPolicy,
 	}), nil
-	if err != nil {
-		return err
-	}
-	printer := printers.GetPrinter(printers.DefaultPrinter(), genericclioptions.IOStreams{
-		In:     os.Stdin,
-		Out:    os.Stdout,
-		ErrOut: os.Stderr,
-	})
-	return printer.Print(ch, dryRunStrategy, true)
+}
+
+// noWaitStatusWatcher reports the applied objects which are still in progress as current,
+// so the applier doesn't wait for them to become ready. Pruned objects are still waited to be deleted.
+type noWaitStatusWatcher struct {
+	watcher.StatusWatcher
+	applied object.ObjMetadataSet
+}
+
+func (w *noWaitStatusWatcher) Watch(ctx context.Context, ids object.ObjMetadataSet, options watcher.Options) <-chan pollevent.Event {
+	events := w.StatusWatcher.Watch(ctx, ids, options)
+	reported := make(chan pollevent.Event)
+	go func() {
+		defer close(reported)
+		for e := range events {
+			if e.Type == pollevent.ResourceUpdateEvent && e.Resource != nil &&
+				e.Resource.Status == kstatus.InProgressStatus && w.applied.Contains(e.Resource.Identifier) {
+				resource := *e.Resource
+				resource.Status = kstatus.CurrentStatus
+				e.Resource = &resource
+			}
+			reported <- e
+		}
+	}()
+	return reported
 }
 
 func (a *KptClient) installResourceGroup(f util.Factory) error {
@@ -277,8 +353,7 @@ func (a *KptClient) InstallResourceGroup() error {
 }
 
 func (c *KptClient) Destroy(object types.NamespacedName, id string) error {
-	invClientFactory := util.NewFactory(c.resourceGroupClientGetter)
-	invClient, err := inventory.NewClient(invClientFactory, live.WrapInventoryObj, live.InvToUnstructuredFunc, inventory.StatusPolicyAll, live.ResourceGroupGVK)
+	invClient, err := c.inventoryClient()
 	if err != nil {
 		return err
 	}
@@ -315,10 +390,6 @@ func (c *KptClient) Destroy(object types.NamespacedName, id string) error {
 	}
 	ch := destroyer.Run(c.ctx, inv, options)
 
-	printer := printers.GetPrinter(printers.DefaultPrinter(), genericclioptions.IOStreams{
-		In:     os.Stdin,
-		Out:    os.Stdout,
-		ErrOut: os.Stderr,
-	})
-	return printer.Print(ch, dryRunStrategy, true)
+	_, err = collectResults(ch, nil)
+	return err
 }
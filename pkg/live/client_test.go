@@ -7,6 +7,10 @@ import (
 	"time"
 
 	"gotest.tools/v3/assert"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/kustomize/api/resmap"
 	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
@@ -15,6 +19,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -79,7 +84,8 @@ func TestKptkptClientApplyWaitForReconcile(t *testing.T) {
 
 	applied := make(chan error)
 	go func() {
-		applied <- kptClient.Apply(build, ApplyOptions{})
+		_, err := kptClient.Apply(build, ApplyOptions{})
+		applied <- err
 	}()
 
 	clientset, err := kubernetes.NewForConfig(cfg)
@@ -164,8 +170,12 @@ func TestKptkptClientApplyWaitForReconcile(t *testing.T) {
 		deletedResourcesBuild.Append(r)
 	}
 
-	err = kptClient.Apply(deletedResourcesBuild, ApplyOptions{})
+	results, err := kptClient.Apply(deletedResourcesBuild, ApplyOptions{})
 	assert.NilError(t, err, "failed to apply")
+	assert.Equal(t, len(results), 2)
+	for _, result := range results {
+		assert.Equal(t, result.Prune, "Successful")
+	}
 	_, err = nginxPodAPI.Get(context.TODO(), "nginx", metav1.GetOptions{})
 	assert.Assert(t, errors.IsNotFound(err), "nginx pod should have been deleted, %s", err)
 }
@@ -241,7 +251,8 @@ func TestKptkptClientDestroyWaitForReconcile(t *testing.T) {
 	err = kptClient.ImpersonateForResources(types.NamespacedName{Namespace: "default", Name: "destroy-wait-reconcile"})
 	assert.NilError(t, err, "failed to set impersonation for kptClient")
 	assert.NilError(t, kptClient.InstallResourceGroup(), "failed to install resource group")
-	assert.NilError(t, kptClient.Apply(build, ApplyOptions{}), "failed to apply resources")
+	_, err = kptClient.Apply(build, ApplyOptions{})
+	assert.NilError(t, err, "failed to apply resources")
 
 	deleted := make(chan error)
 	go func() {
@@ -306,8 +317,12 @@ func TestKptkptClientApplyForbidden(t *testing.T) {
 	err = kptClient.InstallResourceGroup()
 	assert.NilError(t, err, "failed to install resource group")
 
-	err = kptClient.Apply(build, ApplyOptions{})
+	results, err := kptClient.Apply(build, ApplyOptions{})
 	assert.Assert(t, strings.Contains(err.Error(), "forbidden"))
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Identifier.Name, "forbidden")
+	assert.Equal(t, results[0].Apply, "Failed")
+	assert.Assert(t, strings.Contains(results[0].Error, "forbidden"))
 
 	clientset, err := kubernetes.NewForConfig(cfg)
 	assert.NilError(t, err, "failed to create clientset")
@@ -315,3 +330,37 @@ func TestKptkptClientApplyForbidden(t *testing.T) {
 	_, err = clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "forbidden", metav1.GetOptions{})
 	assert.Assert(t, errors.IsNotFound(err), "forbidden configmap should not have been created, %s", err)
 }
+
+type fakeStatusWatcher []pollevent.Event
+
+func (w fakeStatusWatcher) Watch(context.Context, object.ObjMetadataSet, watcher.Options) <-chan pollevent.Event {
+	ch := make(chan pollevent.Event, len(w))
+	for _, e := range w {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func TestNoWaitStatusWatcher(t *testing.T) {
+	applied := object.ObjMetadata{GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"}, Namespace: "default", Name: "applied"}
+	failed := object.ObjMetadata{GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"}, Namespace: "default", Name: "failed"}
+	pruned := object.ObjMetadata{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Namespace: "default", Name: "pruned"}
+
+	w := &noWaitStatusWatcher{
+		StatusWatcher: fakeStatusWatcher{
+			{Type: pollevent.SyncEvent},
+			{Type: pollevent.ResourceUpdateEvent, Resource: &pollevent.ResourceStatus{Identifier: applied, Status: kstatus.InProgressStatus, Message: "progressing"}},
+			{Type: pollevent.ResourceUpdateEvent, Resource: &pollevent.ResourceStatus{Identifier: failed, Status: kstatus.FailedStatus}},
+			{Type: pollevent.ResourceUpdateEvent, Resource: &pollevent.ResourceStatus{Identifier: pruned, Status: kstatus.InProgressStatus}},
+		},
+		applied: object.ObjMetadataSet{applied, failed},
+	}
+	var statuses []kstatus.Status
+	for e := range w.Watch(context.Background(), object.ObjMetadataSet{applied, failed, pruned}, watcher.Options{}) {
+		if e.Type == pollevent.ResourceUpdateEvent {
+			statuses = append(statuses, e.Resource.Status)
+		}
+	}
+	assert.DeepEqual(t, statuses, []kstatus.Status{kstatus.CurrentStatus, kstatus.FailedStatus, kstatus.InProgressStatus})
+}
@@ -0,0 +1,192 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// JobKind is the kind of operation run by a Job
+type JobKind string
+
+const (
+	JobKindApply   JobKind = "Apply"
+	JobKindDestroy JobKind = "Destroy"
+)
+
+type jobKey struct {
+	types.NamespacedName
+	kind JobKind
+}
+
+// Job is an apply or destroy of a Live running on the Executor.
+// The results are set by the function run by the job and can be read once the job is done.
+type Job struct {
+	// ID identifies the attempt of the operation
+	ID string
+	// Generation of the Live the job was started for
+	Generation int64
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+	cancelled bool
+
+	Err     error
+	DryRun  bool
+	Plan    []PlannedObject
+	Objects []ObjectResult
+	Hooks   []HookResult
+
+	mu    sync.Mutex
+	waves []WaveProgress
+}
+
+// SetWaves records the progress of the sync waves of the running job
+func (j *Job) SetWaves(waves []WaveProgress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.waves = waves
+}
+
+// Waves returns the last recorded progress of the sync waves, nil if the resources aren't applied in waves
+func (j *Job) Waves() []WaveProgress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.waves
+}
+
+// Done reports whether the job completed
+func (j *Job) Done() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Cancelled reports whether the job was cancelled before it completed
+func (j *Job) Cancelled() bool {
+	return j.Done() && j.cancelled
+}
+
+// Executor runs the operations on the Lives using a bounded number of workers.
+// At most one job of each kind is tracked per Live. It is safe for concurrent use.
+type Executor struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers chan struct{}
+
+	mu   sync.Mutex
+	jobs map[jobKey]*Job
+}
+
+// NewExecutor creates an executor running at most parallelism jobs at the same time
+func NewExecutor(parallelism int) *Executor {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Executor{
+		ctx:     ctx,
+		cancel:  cancel,
+		workers: make(chan struct{}, parallelism),
+		jobs:    map[jobKey]*Job{},
+	}
+}
+
+// Start blocks until the context is done and then cancels all the jobs
+func (e *Executor) Start(ctx context.Context) error {
+	<-ctx.Done()
+	e.cancel()
+	return nil
+}
+
+// NeedLeaderElection makes sure the jobs run only on the leader, alongside the controllers submitting them
+func (e *Executor) NeedLeaderElection() bool {
+	return true
+}
+
+// Submit queues the job for the Live, replacing any previously tracked job of the same kind.
+// run is called with a context which is cancelled when the job is cancelled, and onDone is called after it returns.
+func (e *Executor) Submit(live types.NamespacedName, kind JobKind, job *Job, run func(ctx context.Context, job *Job), onDone func()) {
+	job.ctx, job.cancel = context.WithCancel(e.ctx)
+	job.done = make(chan struct{})
+
+	e.mu.Lock()
+	if previous, ok := e.jobs[jobKey{live, kind}]; ok {
+		previous.cancel()
+	}
+	e.jobs[jobKey{live, kind}] = job
+	e.mu.Unlock()
+
+	go func() {
+		defer onDone()
+		defer close(job.done)
+
+		select {
+		case e.workers <- struct{}{}:
+		case <-job.ctx.Done():
+			job.Err = job.ctx.Err()
+			job.cancelled = true
+			return
+		}
+		defer func() { <-e.workers }()
+		runJob(job, run)
+		job.cancelled = job.ctx.Err() != nil
+		job.cancel()
+	}()
+}
+
+// runJob runs the job, failing it if run panics so that the worker and the Live aren't stuck on it
+func runJob(job *Job, run func(ctx context.Context, job *Job)) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Log.WithName("executor").Error(fmt.Errorf("%v", r), "job panicked", "id", job.ID, "stack", string(debug.Stack()))
+			job.Err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	run(job.ctx, job)
+}
+
+// Get returns the tracked job of the Live, nil if there is none
+func (e *Executor) Get(live types.NamespacedName, kind JobKind) *Job {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.jobs[jobKey{live, kind}]
+}
+
+// Cancel cancels the tracked job of the Live. The job is still tracked until it's forgotten.
+func (e *Executor) Cancel(live types.NamespacedName, kind JobKind) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if job, ok := e.jobs[jobKey{live, kind}]; ok {
+		job.cancel()
+	}
+}
+
+// Forget stops tracking the job, unless it was already replaced by another job
+func (e *Executor) Forget(live types.NamespacedName, kind JobKind, job *Job) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.jobs[jobKey{live, kind}] == job {
+		delete(e.jobs, jobKey{live, kind})
+	}
+}
+
+// Remove cancels and stops tracking all the jobs of the Live
+func (e *Executor) Remove(live types.NamespacedName) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, kind := range []JobKind{JobKindApply, JobKindDestroy} {
+		if job, ok := e.jobs[jobKey{live, kind}]; ok {
+			job.cancel()
+			delete(e.jobs, jobKey{live, kind})
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func waitDone(t *testing.T, jobs ...*Job) {
+	t.Helper()
+	for _, job := range jobs {
+		select {
+		case <-job.done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("job %s didn't complete", job.ID)
+		}
+	}
+}
+
+func TestExecutorParallelism(t *testing.T) {
+	executor := NewExecutor(2)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	var jobs []*Job
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		job := &Job{ID: name}
+		jobs = append(jobs, job)
+		executor.Submit(types.NamespacedName{Namespace: "default", Name: name}, JobKindApply, job, func(ctx context.Context, job *Job) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			<-release
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}, func() {})
+	}
+	time.Sleep(100 * time.Millisecond)
+	for _, job := range jobs {
+		assert.Assert(t, executor.Get(types.NamespacedName{Namespace: "default", Name: job.ID}, JobKindApply) == job)
+	}
+	close(release)
+	waitDone(t, jobs...)
+	assert.Equal(t, maxRunning, 2)
+	for _, job := range jobs {
+		assert.Assert(t, !job.Cancelled())
+	}
+}
+
+func TestExecutorCancel(t *testing.T) {
+	executor := NewExecutor(1)
+	live := types.NamespacedName{Namespace: "default", Name: "live"}
+	other := types.NamespacedName{Namespace: "default", Name: "other"}
+
+	blocking := &Job{ID: "blocking"}
+	started := make(chan struct{})
+	executor.Submit(other, JobKindApply, blocking, func(ctx context.Context, job *Job) {
+		close(started)
+		<-ctx.Done()
+	}, func() {})
+	<-started
+
+	queued := &Job{ID: "queued"}
+	ran := false
+	executor.Submit(live, JobKindApply, queued, func(ctx context.Context, job *Job) {
+		ran = true
+	}, func() {})
+	executor.Cancel(live, JobKindApply)
+	waitDone(t, queued)
+	assert.Assert(t, !ran)
+	assert.Assert(t, queued.Cancelled())
+	assert.Assert(t, errors.Is(queued.Err, context.Canceled))
+	assert.Assert(t, executor.Get(live, JobKindApply) == queued)
+
+	executor.Cancel(other, JobKindApply)
+	waitDone(t, blocking)
+	assert.Assert(t, blocking.Cancelled())
+}
+
+func TestExecutorReplace(t *testing.T) {
+	executor := NewExecutor(2)
+	live := types.NamespacedName{Namespace: "default", Name: "live"}
+
+	done := make(chan string, 2)
+	previous := &Job{ID: "previous", Generation: 1}
+	executor.Submit(live, JobKindApply, previous, func(ctx context.Context, job *Job) {
+		<-ctx.Done()
+		job.Err = ctx.Err()
+	}, func() { done <- "previous" })
+
+	current := &Job{ID: "current", Generation: 2}
+	executor.Submit(live, JobKindApply, current, func(ctx context.Context, job *Job) {}, func() { done <- "current" })
+	waitDone(t, previous, current)
+	<-done
+	<-done
+	assert.Assert(t, previous.Cancelled())
+	assert.Assert(t, !current.Cancelled())
+	assert.Assert(t, current.Err == nil)
+
+	executor.Forget(live, JobKindApply, previous)
+	assert.Assert(t, executor.Get(live, JobKindApply) == current)
+	executor.Forget(live, JobKindApply, current)
+	assert.Assert(t, executor.Get(live, JobKindApply) == nil)
+}
+
+func TestExecutorRemove(t *testing.T) {
+	executor := NewExecutor(2)
+	live := types.NamespacedName{Namespace: "default", Name: "live"}
+
+	apply := &Job{ID: "apply"}
+	executor.Submit(live, JobKindApply, apply, func(ctx context.Context, job *Job) {
+		<-ctx.Done()
+	}, func() {})
+	destroy := &Job{ID: "destroy"}
+	executor.Submit(live, JobKindDestroy, destroy, func(ctx context.Context, job *Job) {
+		<-ctx.Done()
+	}, func() {})
+
+	executor.Remove(live)
+	waitDone(t, apply, destroy)
+	assert.Assert(t, apply.Cancelled())
+	assert.Assert(t, destroy.Cancelled())
+	assert.Assert(t, executor.Get(live, JobKindApply) == nil)
+	assert.Assert(t, executor.Get(live, JobKindDestroy) == nil)
+}
+
+func TestExecutorStart(t *testing.T) {
+	executor := NewExecutor(1)
+	job := &Job{ID: "job"}
+	executor.Submit(types.NamespacedName{Namespace: "default", Name: "live"}, JobKindApply, job, func(ctx context.Context, job *Job) {
+		<-ctx.Done()
+	}, func() {})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NilError(t, executor.Start(ctx))
+	waitDone(t, job)
+	assert.Assert(t, job.Cancelled())
+}
+
+func TestExecutorPanic(t *testing.T) {
+	executor := NewExecutor(1)
+	panicked := &Job{ID: "panicked"}
+	onDone := make(chan struct{})
+	executor.Submit(types.NamespacedName{Namespace: "default", Name: "a"}, JobKindApply, panicked, func(ctx context.Context, job *Job) {
+		panic("boom")
+	}, func() { close(onDone) })
+	next := &Job{ID: "next"}
+	executor.Submit(types.NamespacedName{Namespace: "default", Name: "b"}, JobKindApply, next, func(ctx context.Context, job *Job) {}, func() {})
+
+	waitDone(t, panicked, next)
+	<-onDone
+	assert.Error(t, panicked.Err, "job panicked: boom")
+	assert.Assert(t, !panicked.Cancelled())
+	assert.NilError(t, next.Err)
+}
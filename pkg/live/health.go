@@ -0,0 +1,166 @@
+package live
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	kptfilev1 "github.com/GoogleContainerTools/kpt/pkg/api/kptfile/v1"
+	"github.com/GoogleContainerTools/kpt/pkg/live"
+	"github.com/GoogleContainerTools/kpt/pkg/status"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubectl/pkg/cmd/util"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/watcher"
+	"sigs.k8s.io/cli-utils/pkg/object"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+)
+
+// HealthWatch continuously watches the status of the objects in the inventory
+type HealthWatch struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	objects object.ObjMetadataSet
+
+	mu       sync.Mutex
+	synced   bool
+	err      error
+	statuses map[object.ObjMetadata]*pollevent.ResourceStatus
+}
+
+// WatchHealth starts watching the status of the objects in the inventory.
+// onChange is called from the watch goroutine every time the status of an object changes after the initial sync.
+func (c *KptClient) WatchHealth(inventoryObject types.NamespacedName, id string, onChange func()) (*HealthWatch, error) {
+	invClient, err := c.inventoryClient()
+	if err != nil {
+		return nil, err
+	}
+	invInfo, err := live.ToInventoryInfo(kptfilev1.Inventory{
+		Name:        inventoryObject.Name,
+		Namespace:   inventoryObject.Namespace,
+		InventoryID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	objects, err := invClient.GetClusterObjs(invInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	statusWatcher, err := status.NewStatusWatcher(util.NewFactory(c.resourceClientGetter))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	w := &HealthWatch{
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		objects:  objects,
+		statuses: map[object.ObjMetadata]*pollevent.ResourceStatus{},
+	}
+	if len(objects) == 0 {
+		w.synced = true
+		close(w.done)
+		return w, nil
+	}
+
+	ch := statusWatcher.Watch(ctx, objects, watcher.Options{})
+	go func() {
+		defer close(w.done)
+		for e := range ch {
+			if w.handle(e) {
+				onChange()
+			}
+		}
+	}()
+	return w, nil
+}
+
+// handle records the event and reports whether the observed health changed
+func (w *HealthWatch) handle(e pollevent.Event) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch e.Type {
+	case pollevent.SyncEvent:
+		w.synced = true
+		return true
+	case pollevent.ErrorEvent:
+		w.err = e.Error
+		return true
+	case pollevent.ResourceUpdateEvent:
+		previous := w.statuses[e.Resource.Identifier]
+		w.statuses[e.Resource.Identifier] = e.Resource
+		if !w.synced {
+			return false
+		}
+		return previous == nil || previous.Status != e.Resource.Status || previous.Message != e.Resource.Message
+	}
+	return false
+}
+
+// Stop stops watching and waits for the watch goroutine to exit
+func (w *HealthWatch) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// Stopped reports whether the watch stopped, either because it was stopped or it failed
+func (w *HealthWatch) Stopped() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Err returns the error which stopped the watch
+func (w *HealthWatch) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Health returns the current status of the watched objects sorted by their identifiers.
+// Objects which didn't exist since the watch started are reported as NotFound.
+// Returns false until the watch synced with the cluster.
+func (w *HealthWatch) Health() ([]kuberikv1alpha1.LiveObjectStatus, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.synced {
+		return nil, false
+	}
+
+	health := []kuberikv1alpha1.LiveObjectStatus{}
+	for _, id := range w.objects {
+		objectStatus := kuberikv1alpha1.LiveObjectStatus{
+			Group:     id.GroupKind.Group,
+			Kind:      id.GroupKind.Kind,
+			Namespace: id.Namespace,
+			Name:      id.Name,
+			Status:    string(kstatus.NotFoundStatus),
+		}
+		if resourceStatus, ok := w.statuses[id]; ok {
+			objectStatus.Status = string(resourceStatus.Status)
+			objectStatus.Message = resourceStatus.Message
+			if resourceStatus.Resource != nil {
+				objectStatus.Version = resourceStatus.Resource.GroupVersionKind().Version
+			}
+		}
+		health = append(health, objectStatus)
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return objectStatusKey(health[i]) < objectStatusKey(health[j])
+	})
+	return health, true
+}
+
+func objectStatusKey(s kuberikv1alpha1.LiveObjectStatus) string {
+	return s.Group + "/" + s.Kind + "/" + s.Namespace + "/" + s.Name
+}
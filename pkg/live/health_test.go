@@ -0,0 +1,71 @@
+package live
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+)
+
+func TestHealthWatch(t *testing.T) {
+	deployment := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "nginx",
+	}
+	service := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "Service"},
+		Namespace: "default",
+		Name:      "nginx",
+	}
+	w := &HealthWatch{
+		objects:  object.ObjMetadataSet{service, deployment},
+		statuses: map[object.ObjMetadata]*pollevent.ResourceStatus{},
+	}
+	update := func(id object.ObjMetadata, status kstatus.Status, message string) bool {
+		resource := &unstructured.Unstructured{}
+		resource.SetAPIVersion("apps/v1")
+		return w.handle(pollevent.Event{
+			Type: pollevent.ResourceUpdateEvent,
+			Resource: &pollevent.ResourceStatus{
+				Identifier: id,
+				Status:     status,
+				Resource:   resource,
+				Message:    message,
+			},
+		})
+	}
+
+	assert.Assert(t, !update(deployment, kstatus.InProgressStatus, "Replicas: 0/1"))
+	_, synced := w.Health()
+	assert.Assert(t, !synced)
+
+	assert.Assert(t, w.handle(pollevent.Event{Type: pollevent.SyncEvent}))
+	health, synced := w.Health()
+	assert.Assert(t, synced)
+	assert.DeepEqual(t, health, []kuberikv1alpha1.LiveObjectStatus{{
+		Kind:      "Service",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "NotFound",
+	}, {
+		Group:     "apps",
+		Version:   "v1",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "nginx",
+		Status:    "InProgress",
+		Message:   "Replicas: 0/1",
+	}})
+
+	assert.Assert(t, !update(deployment, kstatus.InProgressStatus, "Replicas: 0/1"))
+	assert.Assert(t, update(deployment, kstatus.CurrentStatus, "Replicas: 1/1"))
+	health, _ = w.Health()
+	assert.Equal(t, health[1].Status, "Current")
+}
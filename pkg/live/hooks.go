@@ -0,0 +1,250 @@
+package live
+
+import (
+	"fmt"
+	"time"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+const (
+	// HookAnnotation marks a resource as a hook of the phase set as the value
+	HookAnnotation = "kuberik.io/hook"
+	// HookFailurePolicyAnnotation sets what happens when the hook fails, defaults to abort
+	HookFailurePolicyAnnotation = "kuberik.io/hook-failure-policy"
+
+	// DefaultHookTimeout is the maximum duration of waiting for the hooks to complete
+	// when the Live doesn't set a reconcile timeout
+	DefaultHookTimeout = 10 * time.Minute
+)
+
+// HookPhase is the point of the apply at which the hooks are run
+type HookPhase string
+
+const (
+	HookPhasePreApply  HookPhase = "pre-apply"
+	HookPhasePostApply HookPhase = "post-apply"
+)
+
+// HookPhases lists the hook phases in the order they are run
+var HookPhases = []HookPhase{HookPhasePreApply, HookPhasePostApply}
+
+// HookFailurePolicy is what happens with the apply when a hook fails
+type HookFailurePolicy string
+
+const (
+	// HookFailurePolicyAbort fails the apply
+	HookFailurePolicyAbort HookFailurePolicy = "abort"
+	// HookFailurePolicyIgnore continues the apply as if the hook succeeded
+	HookFailurePolicyIgnore HookFailurePolicy = "ignore"
+	// HookFailurePolicyRollback fails the apply and re-applies the previously applied revision
+	HookFailurePolicyRollback HookFailurePolicy = "rollback"
+)
+
+// hookFailurePolicySeverity orders the failure policies, the most severe policy of the failed hooks applies
+var hookFailurePolicySeverity = map[HookFailurePolicy]int{
+	HookFailurePolicyIgnore:   0,
+	HookFailurePolicyAbort:    1,
+	HookFailurePolicyRollback: 2,
+}
+
+// HookResult is the outcome of running the hooks of a phase
+type HookResult struct {
+	Phase HookPhase
+	// Timeout is the maximum duration the hooks were waited on to complete
+	Timeout time.Duration
+	Objects []ObjectResult
+}
+
+// HookInventory returns the name and the ID of the inventory holding the hooks of the phase
+func HookInventory(live types.NamespacedName, inventoryID string, phase HookPhase) (types.NamespacedName, string) {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("%s-%s", live.Name, phase),
+		Namespace: live.Namespace,
+	}, fmt.Sprintf("%s-%s", inventoryID, phase)
+}
+
+func hookFailurePolicy(annotations map[string]string) (HookFailurePolicy, error) {
+	policy := HookFailurePolicy(annotations[HookFailurePolicyAnnotation])
+	if policy == "" {
+		return HookFailurePolicyAbort, nil
+	}
+	if _, ok := hookFailurePolicySeverity[policy]; !ok {
+		return "", fmt.Errorf("unknown hook failure policy %q", policy)
+	}
+	return policy, nil
+}
+
+// extractHooks removes the hook resources from the resources and groups them by phase
+func extractHooks(live *kuberikv1alpha1.Live, resMap resmap.ResMap) (map[HookPhase]resmap.ResMap, error) {
+	hooks := map[HookPhase]resmap.ResMap{}
+	for _, r := range resMap.Resources() {
+		annotations := r.GetAnnotations()
+		value, ok := annotations[HookAnnotation]
+		if !ok {
+			continue
+		}
+		phase := HookPhase(value)
+		if phase != HookPhasePreApply && phase != HookPhasePostApply {
+			return nil, fmt.Errorf("unknown hook phase %q of %s", value, r.CurId())
+		}
+		if _, err := hookFailurePolicy(annotations); err != nil {
+			return nil, fmt.Errorf("invalid hook %s: %v", r.CurId(), err)
+		}
+
+		if _, ok := hooks[phase]; !ok {
+			hooks[phase] = resmap.New()
+		}
+		if err := resMap.Remove(r.CurId()); err != nil {
+			return nil, err
+		}
+		if err := hooks[phase].Append(r); err != nil {
+			return nil, err
+		}
+	}
+
+	for phase, phaseHooks := range hooks {
+		inventory, inventoryID := HookInventory(live.NamespacedName(), live.InventoryID(), phase)
+		resourceGroup, err := newResourceGroup(inventory.Name, inventory.Namespace, inventoryID)
+		if err != nil {
+			return nil, err
+		}
+		if err := phaseHooks.Append(resourceGroup); err != nil {
+			return nil, err
+		}
+	}
+	return hooks, nil
+}
+
+// HasRollbackHooks reports whether any of the post-apply hooks rolls back the apply on failure
+func (a *LiveApply) HasRollbackHooks() bool {
+	hooks, ok := a.Hooks[HookPhasePostApply]
+	if !ok {
+		return false
+	}
+	for _, r := range hooks.Resources() {
+		if policy, _ := hookFailurePolicy(r.GetAnnotations()); policy == HookFailurePolicyRollback {
+			return true
+		}
+	}
+	return false
+}
+
+// failedHooksPolicy returns the most severe failure policy of the failed hooks
+func failedHooksPolicy(objects object.UnstructuredSet, results []ObjectResult) HookFailurePolicy {
+	policies := map[object.ObjMetadata]HookFailurePolicy{}
+	for _, obj := range objects {
+		policies[object.UnstructuredToObjMetadata(obj)], _ = hookFailurePolicy(obj.GetAnnotations())
+	}
+
+	failed := false
+	policy := HookFailurePolicyIgnore
+	for _, result := range results {
+		if !result.Failed() {
+			continue
+		}
+		failed = true
+		resultPolicy, ok := policies[result.Identifier]
+		if !ok {
+			resultPolicy = HookFailurePolicyAbort
+		}
+		if hookFailurePolicySeverity[resultPolicy] > hookFailurePolicySeverity[policy] {
+			policy = resultPolicy
+		}
+	}
+	if !failed {
+		// The hooks failed without any of them failing individually
+		return HookFailurePolicyAbort
+	}
+	return policy
+}
+
+// hookTimeout returns how long the hooks are waited on to complete, hooks never wait indefinitely
+func hookTimeout(options ApplyOptions) time.Duration {
+	if options.ReconcileTimeout > 0 {
+		return options.ReconcileTimeout
+	}
+	return DefaultHookTimeout
+}
+
+// RunHooks deletes the hook objects left from the previous run of the phase, applies the hooks and waits for them to complete
+// within the reconcile timeout, or DefaultHookTimeout if there is none.
+// If the hooks fail, the most severe failure policy of the failed hooks is returned along with the error.
+func (c *KptClient) RunHooks(hooks resmap.ResMap, options ApplyOptions) ([]ObjectResult, HookFailurePolicy, error) {
+	applyObjects, err := newKptApplyObjects(hooks)
+	if err != nil {
+		return nil, HookFailurePolicyAbort, err
+	}
+
+	resourceGroup := applyObjects.resourceGroup
+	if err := c.Destroy(types.NamespacedName{
+		Name:      resourceGroup.GetName(),
+		Namespace: resourceGroup.GetNamespace(),
+	}, resourceGroup.GetLabels()[common.InventoryLabel]); err != nil {
+		return nil, HookFailurePolicyAbort, fmt.Errorf("failed to delete previous hooks: %v", err)
+	}
+
+	// Hooks are always waited on, their completion is their result
+	options.NoWait = false
+	options.ReconcileTimeout = hookTimeout(options)
+	ch, err := c.run(applyObjects, options, common.DryRunNone)
+	if err != nil {
+		return nil, HookFailurePolicyAbort, err
+	}
+	results, err := collectResults(ch, applyObjects.objects)
+	if err != nil {
+		for _, result := range results {
+			if result.Wait == event.ReconcileTimeout.String() {
+				err = fmt.Errorf("%v; hooks didn't complete within %s", err, options.ReconcileTimeout)
+				break
+			}
+		}
+		return results, failedHooksPolicy(applyObjects.objects, results), err
+	}
+	return results, "", nil
+}
+
+// ApplyWithHooks runs the pre-apply hooks, applies the resources and runs the post-apply hooks.
+// previous are the resources of the previously applied revision, used by the post-apply hooks with the rollback failure policy.
+func (c *KptClient) ApplyWithHooks(apply *LiveApply, previous *LiveApply, options ApplyOptions) ([]ObjectResult, []HookResult, error) {
+	var hookResults []HookResult
+	if hooks, ok := apply.Hooks[HookPhasePreApply]; ok {
+		results, policy, err := c.RunHooks(hooks, options)
+		hookResults = append(hookResults, HookResult{Phase: HookPhasePreApply, Timeout: hookTimeout(options), Objects: results})
+		if err != nil && policy != HookFailurePolicyIgnore {
+			// Nothing is applied yet, so rolling back is the same as aborting
+			return nil, hookResults, fmt.Errorf("%s hooks failed: %v", HookPhasePreApply, err)
+		}
+	}
+
+	objects, err := c.Apply(apply.ResMap, options)
+	if err != nil {
+		return objects, hookResults, err
+	}
+
+	hooks, ok := apply.Hooks[HookPhasePostApply]
+	if !ok {
+		return objects, hookResults, nil
+	}
+	results, policy, err := c.RunHooks(hooks, options)
+	hookResults = append(hookResults, HookResult{Phase: HookPhasePostApply, Timeout: hookTimeout(options), Objects: results})
+	if err == nil || policy == HookFailurePolicyIgnore {
+		return objects, hookResults, nil
+	}
+	if policy != HookFailurePolicyRollback {
+		return objects, hookResults, fmt.Errorf("%s hooks failed: %v", HookPhasePostApply, err)
+	}
+	if previous == nil {
+		return objects, hookResults, fmt.Errorf("%s hooks failed: %v; no previous revision to roll back to", HookPhasePostApply, err)
+	}
+	options.OnWaveProgress = nil
+	if _, rollbackErr := c.Apply(previous.ResMap, options); rollbackErr != nil {
+		return objects, hookResults, fmt.Errorf("%s hooks failed: %v; rollback failed: %v", HookPhasePostApply, err, rollbackErr)
+	}
+	return objects, hookResults, fmt.Errorf("%s hooks failed: %v; rolled back to the previous revision", HookPhasePostApply, err)
+}
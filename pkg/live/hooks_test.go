@@ -0,0 +1,181 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/api/resmap"
+	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
+)
+
+func hookJob(name string, annotations map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"namespace":   "default",
+			"annotations": annotations,
+		},
+	}
+}
+
+func resourceNames(resMap resmap.ResMap) []string {
+	var names []string
+	for _, r := range resMap.Resources() {
+		names = append(names, r.GetKind()+"/"+r.GetName())
+	}
+	return names
+}
+
+func TestNewLiveApplyHooks(t *testing.T) {
+	live := &kuberikv1alpha1.Live{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "default",
+			UID:       "uid",
+		},
+	}
+	resources := resmaptest_test.NewRmBuilder(t, rf).
+		Add(hookJob("migrate", map[string]interface{}{HookAnnotation: "pre-apply"})).
+		Add(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "config",
+				"namespace": "default",
+			}}).
+		Add(hookJob("smoke-test", map[string]interface{}{
+			HookAnnotation:              "post-apply",
+			HookFailurePolicyAnnotation: "rollback",
+		})).
+		Add(hookJob("notify", map[string]interface{}{
+			HookAnnotation:              "post-apply",
+			HookFailurePolicyAnnotation: "ignore",
+		})).ResMap()
+
+	apply, err := NewLiveApply(live, resources)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, resourceNames(apply.ResMap), []string{"ConfigMap/config", "ResourceGroup/app"})
+	assert.DeepEqual(t, resourceNames(apply.Hooks[HookPhasePreApply]), []string{"Job/migrate", "ResourceGroup/app-pre-apply"})
+	assert.DeepEqual(t, resourceNames(apply.Hooks[HookPhasePostApply]), []string{"Job/smoke-test", "Job/notify", "ResourceGroup/app-post-apply"})
+	assert.Assert(t, apply.HasRollbackHooks())
+
+	for _, r := range apply.Hooks[HookPhasePostApply].Resources() {
+		if r.GetKind() == "ResourceGroup" {
+			assert.Equal(t, r.GetLabels()[common.InventoryLabel], "uid-post-apply")
+		}
+	}
+	inventory, inventoryID := HookInventory(live.NamespacedName(), live.InventoryID(), HookPhasePostApply)
+	assert.Equal(t, inventory, types.NamespacedName{Namespace: "default", Name: "app-post-apply"})
+	assert.Equal(t, inventoryID, "uid-post-apply")
+
+	apply, err = NewLiveApply(live, resmaptest_test.NewRmBuilder(t, rf).
+		Add(hookJob("migrate", map[string]interface{}{HookAnnotation: "pre-apply"})).ResMap())
+	assert.NilError(t, err)
+	assert.Assert(t, !apply.HasRollbackHooks())
+	_, ok := apply.Hooks[HookPhasePostApply]
+	assert.Assert(t, !ok)
+
+	_, err = NewLiveApply(live, resmaptest_test.NewRmBuilder(t, rf).
+		Add(hookJob("migrate", map[string]interface{}{HookAnnotation: "pre-sync"})).ResMap())
+	assert.ErrorContains(t, err, `unknown hook phase "pre-sync"`)
+
+	_, err = NewLiveApply(live, resmaptest_test.NewRmBuilder(t, rf).
+		Add(hookJob("migrate", map[string]interface{}{
+			HookAnnotation:              "pre-apply",
+			HookFailurePolicyAnnotation: "retry",
+		})).ResMap())
+	assert.ErrorContains(t, err, `unknown hook failure policy "retry"`)
+}
+
+func TestFailedHooksPolicy(t *testing.T) {
+	hook := func(name, policy string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("batch/v1")
+		obj.SetKind("Job")
+		obj.SetNamespace("default")
+		obj.SetName(name)
+		if policy != "" {
+			obj.SetAnnotations(map[string]string{HookFailurePolicyAnnotation: policy})
+		}
+		return obj
+	}
+	result := func(name string, failed bool) ObjectResult {
+		result := ObjectResult{
+			Identifier: object.ObjMetadata{
+				GroupKind: schema.GroupKind{Group: "batch", Kind: "Job"},
+				Namespace: "default",
+				Name:      name,
+			},
+			Apply: "Successful",
+			Wait:  "Successful",
+		}
+		if failed {
+			result.Wait = "Failed"
+		}
+		return result
+	}
+	objects := object.UnstructuredSet{
+		hook("default", ""),
+		hook("ignore", "ignore"),
+		hook("rollback", "rollback"),
+	}
+
+	assert.Equal(t, failedHooksPolicy(objects, []ObjectResult{
+		result("default", false), result("ignore", true), result("rollback", false),
+	}), HookFailurePolicyIgnore)
+	assert.Equal(t, failedHooksPolicy(objects, []ObjectResult{
+		result("default", true), result("ignore", true), result("rollback", false),
+	}), HookFailurePolicyAbort)
+	assert.Equal(t, failedHooksPolicy(objects, []ObjectResult{
+		result("default", true), result("ignore", true), result("rollback", true),
+	}), HookFailurePolicyRollback)
+	assert.Equal(t, failedHooksPolicy(objects, []ObjectResult{
+		result("default", false), result("ignore", false), result("rollback", false),
+	}), HookFailurePolicyAbort)
+}
+
+func TestHookStatuses(t *testing.T) {
+	migrate := object.ObjMetadata{GroupKind: schema.GroupKind{Group: "batch", Kind: "Job"}, Namespace: "default", Name: "migrate"}
+	smokeTest := object.ObjMetadata{GroupKind: schema.GroupKind{Group: "batch", Kind: "Job"}, Namespace: "default", Name: "smoke-test"}
+
+	statuses := HookStatuses([]HookResult{{
+		Phase:   HookPhasePreApply,
+		Timeout: DefaultHookTimeout,
+		Objects: []ObjectResult{{Identifier: migrate, Version: "v1", Apply: "Successful", Wait: "Successful"}},
+	}, {
+		Phase:   HookPhasePostApply,
+		Timeout: DefaultHookTimeout,
+		Objects: []ObjectResult{{Identifier: smokeTest, Version: "v1", Apply: "Successful", Wait: "Failed", Status: "Failed"}},
+	}})
+	assert.DeepEqual(t, statuses, []kuberikv1alpha1.LiveHookStatus{{
+		Phase:   "pre-apply",
+		Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+		LiveObjectStatus: kuberikv1alpha1.LiveObjectStatus{
+			Group: "batch", Version: "v1", Kind: "Job", Namespace: "default", Name: "migrate",
+			Apply: "Successful", Wait: "Successful",
+		},
+	}, {
+		Phase:   "post-apply",
+		Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+		LiveObjectStatus: kuberikv1alpha1.LiveObjectStatus{
+			Group: "batch", Version: "v1", Kind: "Job", Namespace: "default", Name: "smoke-test",
+			Apply: "Successful", Wait: "Failed", Status: "Failed",
+		},
+	}})
+}
+
+func TestHookTimeout(t *testing.T) {
+	assert.Equal(t, hookTimeout(ApplyOptions{}), DefaultHookTimeout)
+	assert.Equal(t, hookTimeout(ApplyOptions{NoWait: true}), DefaultHookTimeout)
+	assert.Equal(t, hookTimeout(ApplyOptions{ReconcileTimeout: time.Minute}), time.Minute)
+}
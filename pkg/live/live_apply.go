@@ -5,7 +5,9 @@ import (
 
 	resourcegroupv1alpha1 "github.com/GoogleContainerTools/kpt/pkg/api/resourcegroup/v1alpha1"
 	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/resource"
@@ -14,6 +16,8 @@ import (
 
 type LiveApply struct {
 	resmap.ResMap
+	// Hooks holds the hook resources of each phase, along with the ResourceGroup of the phase
+	Hooks map[HookPhase]resmap.ResMap
 }
 
 func generateResourceGroup(live *kuberikv1alpha1.Live) (*resource.Resource, error) {
@@ -25,7 +29,10 @@ func generateResourceGroup(live *kuberikv1alpha1.Live) (*resource.Resource, erro
 	if namespace == "" {
 		return nil, fmt.Errorf("live resource must have a namespace")
 	}
+	return newResourceGroup(name, namespace, live.InventoryID())
+}
 
+func newResourceGroup(name, namespace, inventoryID string) (*resource.Resource, error) {
 	var depProvider = provider.NewDefaultDepProvider()
 	var rf = depProvider.GetResourceFactory()
 	return rf.FromMap(map[string]interface{}{
@@ -35,7 +42,7 @@ func generateResourceGroup(live *kuberikv1alpha1.Live) (*resource.Resource, erro
 			"name":      name,
 			"namespace": namespace,
 			"labels": map[string]interface{}{
-				common.InventoryLabel: live.InventoryID(),
+				common.InventoryLabel: inventoryID,
 			},
 		}}), nil
 }
@@ -49,6 +56,11 @@ func NewLiveApply(live *kuberikv1alpha1.Live, resMap resmap.ResMap) (*LiveApply,
 		}
 	}
 
+	hooks, err := extractHooks(live, resMap)
+	if err != nil {
+		return nil, err
+	}
+
 	resourceGroup, err := generateResourceGroup(live)
 	if err != nil {
 		return nil, err
@@ -59,5 +71,128 @@ func NewLiveApply(live *kuberikv1alpha1.Live, resMap resmap.ResMap) (*LiveApply,
 
 	return &LiveApply{
 		ResMap: resMap,
+		Hooks:  hooks,
 	}, nil
 }
+
+// NewApplyOptions returns the options for applying the resources of the Live
+func NewApplyOptions(live *kuberikv1alpha1.Live) ApplyOptions {
+	options := ApplyOptions{}
+	spec := live.Spec.Apply
+	if spec == nil {
+		return options
+	}
+
+	options.NoWait = spec.WaitForReady != nil && !*spec.WaitForReady
+	if spec.ReconcileTimeout != nil && !options.NoWait {
+		options.ReconcileTimeout = spec.ReconcileTimeout.Duration
+	}
+	if spec.PruneTimeout != nil {
+		options.PruneTimeout = spec.PruneTimeout.Duration
+	}
+	options.PrunePropagationPolicy = spec.PrunePropagationPolicy
+
+	var inventoryPolicy inventory.Policy
+	switch spec.InventoryPolicy {
+	case kuberikv1alpha1.LiveInventoryPolicyAdoptAll:
+		inventoryPolicy = inventory.PolicyAdoptAll
+		options.InventoryPolicy = &inventoryPolicy
+	case kuberikv1alpha1.LiveInventoryPolicyMustMatch:
+		inventoryPolicy = inventory.PolicyMustMatch
+		options.InventoryPolicy = &inventoryPolicy
+	}
+
+	return options
+}
+
+// planActionOrder orders the planned objects in the status, failed objects come first, followed by the changed ones
+var planActionOrder = []PlanAction{PlanActionFail, PlanActionCreate, PlanActionUpdate, PlanActionPrune, PlanActionSkip, PlanActionUnchanged}
+
+// PlanStatus converts the plan of the dry-run apply to the status of the Live.
+// Failed and changed objects are listed first and the number of listed objects is limited to kuberikv1alpha1.MaxLiveObjectStatuses.
+func PlanStatus(plan []PlannedObject) []kuberikv1alpha1.LivePlannedObject {
+	ordered := make([]PlannedObject, 0, len(plan))
+	for _, action := range planActionOrder {
+		for _, planned := range plan {
+			if planned.Action == action {
+				ordered = append(ordered, planned)
+			}
+		}
+	}
+	if len(ordered) > kuberikv1alpha1.MaxLiveObjectStatuses {
+		ordered = ordered[:kuberikv1alpha1.MaxLiveObjectStatuses]
+	}
+
+	status := []kuberikv1alpha1.LivePlannedObject{}
+	for _, planned := range ordered {
+		status = append(status, kuberikv1alpha1.LivePlannedObject{
+			Group:     planned.Identifier.GroupKind.Group,
+			Kind:      planned.Identifier.GroupKind.Kind,
+			Namespace: planned.Identifier.Namespace,
+			Name:      planned.Identifier.Name,
+			Action:    string(planned.Action),
+			Message:   planned.Message,
+		})
+	}
+	return status
+}
+
+// ObjectStatuses converts the results of the apply to the status of the Live.
+// Failed objects are listed first and the number of listed objects is limited to kuberikv1alpha1.MaxLiveObjectStatuses.
+func ObjectStatuses(results []ObjectResult) []kuberikv1alpha1.LiveObjectStatus {
+	ordered := make([]ObjectResult, 0, len(results))
+	for _, result := range results {
+		if result.Failed() {
+			ordered = append(ordered, result)
+		}
+	}
+	for _, result := range results {
+		if !result.Failed() {
+			ordered = append(ordered, result)
+		}
+	}
+	if len(ordered) > kuberikv1alpha1.MaxLiveObjectStatuses {
+		ordered = ordered[:kuberikv1alpha1.MaxLiveObjectStatuses]
+	}
+
+	status := []kuberikv1alpha1.LiveObjectStatus{}
+	for _, result := range ordered {
+		status = append(status, objectStatus(result))
+	}
+	return status
+}
+
+// HookStatuses converts the results of the hooks to the status of the Live.
+// The number of listed hooks is limited to kuberikv1alpha1.MaxLiveObjectStatuses.
+func HookStatuses(results []HookResult) []kuberikv1alpha1.LiveHookStatus {
+	status := []kuberikv1alpha1.LiveHookStatus{}
+	for _, phase := range results {
+		for _, result := range phase.Objects {
+			if len(status) == kuberikv1alpha1.MaxLiveObjectStatuses {
+				return status
+			}
+			status = append(status, kuberikv1alpha1.LiveHookStatus{
+				Phase:            string(phase.Phase),
+				Timeout:          &metav1.Duration{Duration: phase.Timeout},
+				LiveObjectStatus: objectStatus(result),
+			})
+		}
+	}
+	return status
+}
+
+func objectStatus(result ObjectResult) kuberikv1alpha1.LiveObjectStatus {
+	return kuberikv1alpha1.LiveObjectStatus{
+		Group:     result.Identifier.GroupKind.Group,
+		Version:   result.Version,
+		Kind:      result.Identifier.GroupKind.Kind,
+		Namespace: result.Identifier.Namespace,
+		Name:      result.Identifier.Name,
+		Apply:     result.Apply,
+		Prune:     result.Prune,
+		Wait:      result.Wait,
+		Error:     result.Error,
+		Status:    result.Status,
+		Message:   result.StatusMessage,
+	}
+}
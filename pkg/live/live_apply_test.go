@@ -2,10 +2,12 @@ package live
 
 import (
 	"testing"
+	"time"
 
 	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
 	"gotest.tools/v3/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/kustomize/api/resmap"
 	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
 )
@@ -122,3 +124,72 @@ func TestNewLiveApply(t *testing.T) {
 		})
 	}
 }
+
+func TestNewApplyOptions(t *testing.T) {
+	noWait := false
+	mustMatch := inventory.PolicyMustMatch
+	adoptAll := inventory.PolicyAdoptAll
+	testCases := []struct {
+		name  string
+		apply *kuberikv1alpha1.LiveApplySpec
+		want  ApplyOptions
+	}{{
+		name: "defaults",
+		want: ApplyOptions{},
+	}, {
+		name: "timeouts",
+		apply: &kuberikv1alpha1.LiveApplySpec{
+			ReconcileTimeout:       &metav1.Duration{Duration: time.Minute},
+			PruneTimeout:           &metav1.Duration{Duration: time.Second * 30},
+			PrunePropagationPolicy: metav1.DeletePropagationForeground,
+		},
+		want: ApplyOptions{
+			ReconcileTimeout:       time.Minute,
+			PruneTimeout:           time.Second * 30,
+			PrunePropagationPolicy: metav1.DeletePropagationForeground,
+		},
+	}, {
+		name: "must-match",
+		apply: &kuberikv1alpha1.LiveApplySpec{
+			InventoryPolicy: kuberikv1alpha1.LiveInventoryPolicyMustMatch,
+			WaitForReady:    &noWait,
+		},
+		want: ApplyOptions{
+			InventoryPolicy: &mustMatch,
+			NoWait:          true,
+		},
+	}, {
+		name: "no-wait",
+		apply: &kuberikv1alpha1.LiveApplySpec{
+			ReconcileTimeout: &metav1.Duration{Duration: time.Minute},
+			WaitForReady:     &noWait,
+		},
+		want: ApplyOptions{
+			NoWait: true,
+		},
+	}, {
+		name: "adopt-all",
+		apply: &kuberikv1alpha1.LiveApplySpec{
+			InventoryPolicy: kuberikv1alpha1.LiveInventoryPolicyAdoptAll,
+		},
+		want: ApplyOptions{
+			InventoryPolicy: &adoptAll,
+		},
+	}, {
+		name: "adopt-if-no-inventory",
+		apply: &kuberikv1alpha1.LiveApplySpec{
+			InventoryPolicy: kuberikv1alpha1.LiveInventoryPolicyAdoptIfNoInventory,
+		},
+		want: ApplyOptions{},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			live := &kuberikv1alpha1.Live{
+				Spec: kuberikv1alpha1.LiveSpec{
+					Apply: tc.apply,
+				},
+			}
+			assert.DeepEqual(t, NewApplyOptions(live), tc.want)
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package live
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+// PlanAction is the change which would be made to an object by applying the resources
+type PlanAction string
+
+const (
+	PlanActionCreate    PlanAction = "Create"
+	PlanActionUpdate    PlanAction = "Update"
+	PlanActionUnchanged PlanAction = "Unchanged"
+	PlanActionPrune     PlanAction = "Prune"
+	PlanActionSkip      PlanAction = "Skip"
+	PlanActionFail      PlanAction = "Fail"
+)
+
+// PlannedObject is the change which would be made to an object by applying the resources
+type PlannedObject struct {
+	Identifier object.ObjMetadata
+	Action     PlanAction
+	Message    string
+}
+
+// Plan applies the resources using server-side dry-run and returns the changes which would be made
+func (c *KptClient) Plan(resMap resmap.ResMap, options ApplyOptions) ([]PlannedObject, error) {
+	applyObjects, err := newKptApplyObjects(resMap)
+	if err != nil {
+		return nil, err
+	}
+
+	currentObjects, err := c.getCurrentObjects(applyObjects.objects)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := c.run(applyObjects, options, common.DryRunServer)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []PlannedObject
+	var planErr error
+	for e := range ch {
+		switch e.Type {
+		case event.ErrorType:
+			planErr = e.ErrorEvent.Err
+		case event.ApplyType:
+			planned := PlannedObject{Identifier: e.ApplyEvent.Identifier}
+			switch e.ApplyEvent.Status {
+			case event.ApplySuccessful:
+				planned.Action = planApplyAction(currentObjects[e.ApplyEvent.Identifier], e.ApplyEvent.Resource)
+			case event.ApplySkipped:
+				planned.Action = PlanActionSkip
+			case event.ApplyFailed:
+				planned.Action = PlanActionFail
+			default:
+				continue
+			}
+			if e.ApplyEvent.Error != nil {
+				planned.Message = e.ApplyEvent.Error.Error()
+			}
+			plan = append(plan, planned)
+		case event.PruneType:
+			planned := PlannedObject{Identifier: e.PruneEvent.Identifier}
+			switch e.PruneEvent.Status {
+			case event.PruneSuccessful:
+				planned.Action = PlanActionPrune
+			case event.PruneSkipped:
+				planned.Action = PlanActionSkip
+			case event.PruneFailed:
+				planned.Action = PlanActionFail
+			default:
+				continue
+			}
+			if e.PruneEvent.Error != nil {
+				planned.Message = e.PruneEvent.Error.Error()
+			}
+			plan = append(plan, planned)
+		}
+	}
+	if planErr != nil {
+		return plan, planErr
+	}
+
+	failed := 0
+	for _, planned := range plan {
+		if planned.Action == PlanActionFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return plan, fmt.Errorf("%d objects failed dry-run", failed)
+	}
+	return plan, nil
+}
+
+// getCurrentObjects returns the objects as they currently exist in the cluster
+func (c *KptClient) getCurrentObjects(objects object.UnstructuredSet) (map[object.ObjMetadata]*unstructured.Unstructured, error) {
+	factory := util.NewFactory(c.resourceClientGetter)
+	dynamicClient, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[object.ObjMetadata]*unstructured.Unstructured{}
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			// Type isn't known yet (e.g. CRD is applied together with the resources)
+			continue
+		}
+		resourceClient := dynamicClient.Resource(mapping.Resource)
+		var currentObj *unstructured.Unstructured
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			currentObj, err = resourceClient.Namespace(obj.GetNamespace()).Get(c.ctx, obj.GetName(), metav1.GetOptions{})
+		} else {
+			currentObj, err = resourceClient.Get(c.ctx, obj.GetName(), metav1.GetOptions{})
+		}
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		current[object.UnstructuredToObjMetadata(obj)] = currentObj
+	}
+	return current, nil
+}
+
+// planApplyAction compares the object in the cluster with the result of the dry-run
+func planApplyAction(current, dryRun *unstructured.Unstructured) PlanAction {
+	if current == nil {
+		return PlanActionCreate
+	}
+	if dryRun == nil || !equality.Semantic.DeepEqual(comparableObject(current), comparableObject(dryRun)) {
+		return PlanActionUpdate
+	}
+	return PlanActionUnchanged
+}
+
+// comparableObject strips the fields which are changed by the server on every apply
+func comparableObject(obj *unstructured.Unstructured) map[string]interface{} {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
+	return obj.Object
+}
@@ -0,0 +1,137 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	resmaptest_test "sigs.k8s.io/kustomize/api/testutils/resmaptest"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+)
+
+func TestPlanApplyAction(t *testing.T) {
+	configMap := func(data string, resourceVersion string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            "plan",
+				"namespace":       "default",
+				"resourceVersion": resourceVersion,
+				"managedFields":   []interface{}{map[string]interface{}{"manager": resourceVersion}},
+			},
+			"data": map[string]interface{}{
+				"key": data,
+			},
+		}}
+	}
+
+	assert.Equal(t, planApplyAction(nil, configMap("a", "1")), PlanActionCreate)
+	assert.Equal(t, planApplyAction(configMap("a", "1"), configMap("a", "2")), PlanActionUnchanged)
+	assert.Equal(t, planApplyAction(configMap("a", "1"), configMap("b", "2")), PlanActionUpdate)
+	assert.Equal(t, planApplyAction(configMap("a", "1"), nil), PlanActionUpdate)
+}
+
+func TestPlanStatus(t *testing.T) {
+	assert.DeepEqual(t, PlanStatus([]PlannedObject{{
+		Identifier: object.ObjMetadata{
+			GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+			Namespace: "default",
+			Name:      "created",
+		},
+		Action: PlanActionCreate,
+	}, {
+		Identifier: object.ObjMetadata{
+			GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+			Name:      "failed",
+		},
+		Action:  PlanActionFail,
+		Message: "forbidden",
+	}}), []kuberikv1alpha1.LivePlannedObject{{
+		Kind:    "ConfigMap",
+		Name:    "failed",
+		Action:  "Fail",
+		Message: "forbidden",
+	}, {
+		Group:     "apps",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "created",
+		Action:    "Create",
+	}})
+
+	var plan []PlannedObject
+	for i := 0; i < kuberikv1alpha1.MaxLiveObjectStatuses+10; i++ {
+		plan = append(plan, PlannedObject{
+			Identifier: object.ObjMetadata{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Name: fmt.Sprintf("cm-%d", i)},
+			Action:     PlanActionUnchanged,
+		})
+	}
+	plan = append(plan, PlannedObject{
+		Identifier: object.ObjMetadata{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Name: "updated"},
+		Action:     PlanActionUpdate,
+	})
+	status := PlanStatus(plan)
+	assert.Equal(t, len(status), kuberikv1alpha1.MaxLiveObjectStatuses)
+	assert.Equal(t, status[0].Name, "updated")
+	assert.Equal(t, status[1].Name, "cm-0")
+}
+
+func TestKptClientPlan(t *testing.T) {
+	build := resmaptest_test.NewRmBuilder(t, rf).
+		Add(map[string]interface{}{
+			"apiVersion": "kpt.dev/v1alpha1",
+			"kind":       "ResourceGroup",
+			"metadata": map[string]interface{}{
+				"name":      "plan",
+				"namespace": "default",
+				"labels": map[string]interface{}{
+					"cli-utils.sigs.k8s.io/inventory-id": "plan-id",
+				},
+			}}).
+		Add(map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "plan",
+				"namespace": "default",
+			},
+			"data": map[string]interface{}{
+				"key": "value",
+			}}).ResMap()
+
+	cfg := initEnvTest(t)
+
+	kptClient, err := NewKptClient(context.TODO(), *cfg)
+	assert.NilError(t, err, "failed to create kptClient")
+
+	err = kptClient.InstallResourceGroup()
+	assert.NilError(t, err, "failed to install resource group")
+
+	plan, err := kptClient.Plan(build, ApplyOptions{})
+	assert.NilError(t, err, "failed to plan")
+	assert.Equal(t, len(plan), 1)
+	assert.Equal(t, plan[0].Identifier.Name, "plan")
+	assert.Equal(t, plan[0].Action, PlanActionCreate)
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	assert.NilError(t, err, "failed to create clientset")
+	_, err = clientset.CoreV1().ConfigMaps("default").Get(context.TODO(), "plan", metav1.GetOptions{})
+	assert.Assert(t, errors.IsNotFound(err), "plan configmap should not have been created, %s", err)
+
+	_, err = kptClient.Apply(build, ApplyOptions{})
+	assert.NilError(t, err, "failed to apply")
+
+	plan, err = kptClient.Plan(build, ApplyOptions{})
+	assert.NilError(t, err, "failed to plan")
+	assert.Equal(t, len(plan), 1)
+	assert.Equal(t, plan[0].Action, PlanActionUnchanged)
+}
@@ -0,0 +1,161 @@
+package live
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// ObjectResult is the outcome of applying, pruning, deleting and waiting for a single object
+type ObjectResult struct {
+	Identifier object.ObjMetadata
+	Version    string
+	// Apply is the status of applying the object, empty if it wasn't applied
+	Apply string
+	// Prune is the status of pruning the object, empty if it wasn't pruned
+	Prune string
+	// Delete is the status of deleting the object, empty if it wasn't deleted
+	Delete string
+	// Wait is the status of waiting for the object to reconcile, empty if it wasn't waited on
+	Wait  string
+	Error string
+	// Status is the last observed kstatus of the object
+	Status        string
+	StatusMessage string
+}
+
+// Failed reports whether any of the actions on the object failed
+func (r ObjectResult) Failed() bool {
+	return r.Apply == event.ApplyFailed.String() ||
+		r.Prune == event.PruneFailed.String() ||
+		r.Delete == event.DeleteFailed.String() ||
+		r.Wait == event.ReconcileFailed.String() ||
+		r.Wait == event.ReconcileTimeout.String() ||
+		r.Error != ""
+}
+
+func (r ObjectResult) String() string {
+	namespacedName := r.Identifier.Name
+	if r.Identifier.Namespace != "" {
+		namespacedName = r.Identifier.Namespace + "/" + namespacedName
+	}
+	return fmt.Sprintf("%s %s", r.Identifier.GroupKind.String(), namespacedName)
+}
+
+// objectResults collects the results of the objects in the order they were first seen in the events
+type objectResults struct {
+	results  []ObjectResult
+	index    map[object.ObjMetadata]int
+	versions map[object.ObjMetadata]string
+}
+
+func newObjectResults(objects object.UnstructuredSet) *objectResults {
+	versions := map[object.ObjMetadata]string{}
+	for _, obj := range objects {
+		versions[object.UnstructuredToObjMetadata(obj)] = obj.GroupVersionKind().Version
+	}
+	return &objectResults{
+		index:    map[object.ObjMetadata]int{},
+		versions: versions,
+	}
+}
+
+func (r *objectResults) get(id object.ObjMetadata) *ObjectResult {
+	i, ok := r.index[id]
+	if !ok {
+		i = len(r.results)
+		r.index[id] = i
+		r.results = append(r.results, ObjectResult{Identifier: id, Version: r.versions[id]})
+	}
+	return &r.results[i]
+}
+
+// handle records the outcome carried by the event, ignoring the events without one
+func (r *objectResults) handle(e event.Event) {
+	switch e.Type {
+	case event.ValidationType:
+		for _, id := range e.ValidationEvent.Identifiers {
+			r.get(id).Error = e.ValidationEvent.Error.Error()
+		}
+	case event.ApplyType:
+		if e.ApplyEvent.Status == event.ApplyPending {
+			return
+		}
+		result := r.get(e.ApplyEvent.Identifier)
+		result.Apply = e.ApplyEvent.Status.String()
+		if e.ApplyEvent.Error != nil {
+			result.Error = e.ApplyEvent.Error.Error()
+		}
+	case event.PruneType:
+		if e.PruneEvent.Status == event.PrunePending {
+			return
+		}
+		result := r.get(e.PruneEvent.Identifier)
+		result.Prune = e.PruneEvent.Status.String()
+		if e.PruneEvent.Error != nil {
+			result.Error = e.PruneEvent.Error.Error()
+		}
+	case event.DeleteType:
+		if e.DeleteEvent.Status == event.DeletePending {
+			return
+		}
+		result := r.get(e.DeleteEvent.Identifier)
+		result.Delete = e.DeleteEvent.Status.String()
+		if e.DeleteEvent.Error != nil {
+			result.Error = e.DeleteEvent.Error.Error()
+		}
+	case event.StatusType:
+		if e.StatusEvent.PollResourceInfo == nil {
+			return
+		}
+		result := r.get(e.StatusEvent.Identifier)
+		result.Status = e.StatusEvent.PollResourceInfo.Status.String()
+		result.StatusMessage = e.StatusEvent.PollResourceInfo.Message
+	case event.WaitType:
+		if e.WaitEvent.Status == event.ReconcilePending {
+			return
+		}
+		r.get(e.WaitEvent.Identifier).Wait = e.WaitEvent.Status.String()
+	}
+}
+
+// err summarizes the failed objects
+func (r *objectResults) err() error {
+	var failed []ObjectResult
+	for _, result := range r.results {
+		if result.Failed() {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	first := failed[0]
+	reason := first.Error
+	if reason == "" {
+		reason = fmt.Sprintf("reconcile %s", first.Wait)
+	}
+	if len(failed) == 1 {
+		return fmt.Errorf("%s failed: %s", first, reason)
+	}
+	return fmt.Errorf("%d objects failed, %s: %s", len(failed), first, reason)
+}
+
+// collectResults consumes the applier or destroyer events until the channel is closed
+func collectResults(ch <-chan event.Event, objects object.UnstructuredSet) ([]ObjectResult, error) {
+	results := newObjectResults(objects)
+	var runErr error
+	for e := range ch {
+		if e.Type == event.ErrorType {
+			runErr = e.ErrorEvent.Err
+			continue
+		}
+		results.handle(e)
+	}
+	if runErr != nil {
+		return results.results, runErr
+	}
+	return results.results, results.err()
+}
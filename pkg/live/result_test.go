@@ -0,0 +1,150 @@
+package live
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	kstatus "sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+)
+
+func TestCollectResults(t *testing.T) {
+	deployment := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+		Namespace: "default",
+		Name:      "nginx",
+	}
+	configMap := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+		Namespace: "default",
+		Name:      "forbidden",
+	}
+	pruned := object.ObjMetadata{
+		GroupKind: schema.GroupKind{Kind: "Service"},
+		Namespace: "default",
+		Name:      "old",
+	}
+	objects := object.UnstructuredSet{
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "nginx",
+				"namespace": "default",
+			},
+		}},
+	}
+
+	run := func(events ...event.Event) ([]ObjectResult, error) {
+		ch := make(chan event.Event, len(events))
+		for _, e := range events {
+			ch <- e
+		}
+		close(ch)
+		return collectResults(ch, objects)
+	}
+
+	results, err := run(
+		event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: deployment, Status: event.ApplyPending}},
+		event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: deployment, Status: event.ApplySuccessful}},
+		event.Event{Type: event.PruneType, PruneEvent: event.PruneEvent{Identifier: pruned, Status: event.PruneSuccessful}},
+		event.Event{Type: event.StatusType, StatusEvent: event.StatusEvent{Identifier: deployment, PollResourceInfo: &pollevent.ResourceStatus{
+			Identifier: deployment,
+			Status:     kstatus.CurrentStatus,
+			Message:    "Deployment is available. Replicas: 1",
+		}}},
+		event.Event{Type: event.WaitType, WaitEvent: event.WaitEvent{Identifier: deployment, Status: event.ReconcileSuccessful}},
+	)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results, []ObjectResult{
+		{Identifier: deployment, Version: "v1", Apply: "Successful", Wait: "Successful", Status: "Current", StatusMessage: "Deployment is available. Replicas: 1"},
+		{Identifier: pruned, Prune: "Successful"},
+	})
+
+	results, err = run(
+		event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: deployment, Status: event.ApplySuccessful}},
+		event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: configMap, Status: event.ApplyFailed, Error: errors.New("forbidden")}},
+		event.Event{Type: event.WaitType, WaitEvent: event.WaitEvent{Identifier: deployment, Status: event.ReconcileTimeout}},
+	)
+	assert.Error(t, err, "2 objects failed, Deployment.apps default/nginx: reconcile Timeout")
+	assert.DeepEqual(t, results, []ObjectResult{
+		{Identifier: deployment, Version: "v1", Apply: "Successful", Wait: "Timeout"},
+		{Identifier: configMap, Apply: "Failed", Error: "forbidden"},
+	})
+
+	_, err = run(
+		event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: configMap, Status: event.ApplyFailed, Error: errors.New("forbidden")}},
+	)
+	assert.Error(t, err, "ConfigMap default/forbidden failed: forbidden")
+
+	results, err = run(
+		event.Event{Type: event.ValidationType, ValidationEvent: event.ValidationEvent{Identifiers: object.ObjMetadataSet{configMap}, Error: errors.New("invalid")}},
+		event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: configMap, Status: event.ApplySkipped}},
+	)
+	assert.Error(t, err, "ConfigMap default/forbidden failed: invalid")
+	assert.DeepEqual(t, results, []ObjectResult{
+		{Identifier: configMap, Apply: "Skipped", Error: "invalid"},
+	})
+
+	results, err = run(
+		event.Event{Type: event.DeleteType, DeleteEvent: event.DeleteEvent{Identifier: deployment, Status: event.DeletePending}},
+		event.Event{Type: event.DeleteType, DeleteEvent: event.DeleteEvent{Identifier: deployment, Status: event.DeleteSuccessful}},
+		event.Event{Type: event.DeleteType, DeleteEvent: event.DeleteEvent{Identifier: configMap, Status: event.DeleteFailed, Error: errors.New("forbidden")}},
+	)
+	assert.Error(t, err, "ConfigMap default/forbidden failed: forbidden")
+	assert.DeepEqual(t, results, []ObjectResult{
+		{Identifier: deployment, Version: "v1", Delete: "Successful"},
+		{Identifier: configMap, Delete: "Failed", Error: "forbidden"},
+	})
+
+	_, err = run(
+		event.Event{Type: event.ErrorType, ErrorEvent: event.ErrorEvent{Err: errors.New("inventory failure")}},
+	)
+	assert.Error(t, err, "inventory failure")
+}
+
+func TestObjectStatuses(t *testing.T) {
+	var results []ObjectResult
+	for i := 0; i < kuberikv1alpha1.MaxLiveObjectStatuses+10; i++ {
+		results = append(results, ObjectResult{
+			Identifier: object.ObjMetadata{
+				GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+				Namespace: "default",
+				Name:      fmt.Sprintf("cm-%d", i),
+			},
+			Version: "v1",
+			Apply:   "Successful",
+		})
+	}
+	results = append(results, ObjectResult{
+		Identifier: object.ObjMetadata{
+			GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+			Namespace: "default",
+			Name:      "failed",
+		},
+		Version: "v1",
+		Apply:   "Failed",
+		Error:   "forbidden",
+	})
+
+	statuses := ObjectStatuses(results)
+	assert.Equal(t, len(statuses), kuberikv1alpha1.MaxLiveObjectStatuses)
+	assert.DeepEqual(t, statuses[0], kuberikv1alpha1.LiveObjectStatus{
+		Group:     "apps",
+		Version:   "v1",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "failed",
+		Apply:     "Failed",
+		Error:     "forbidden",
+	})
+	assert.Equal(t, statuses[1].Name, "cm-0")
+}
@@ -0,0 +1,187 @@
+package live
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+// SyncWaveAnnotation sets the wave in which the object is applied, defaults to 0.
+// Waves are applied in ascending order and the objects of a wave are applied
+// only once all the objects of the previous wave are reconciled.
+const SyncWaveAnnotation = "kuberik.io/sync-wave"
+
+// WaveProgress is the progress of applying the objects of a wave
+type WaveProgress struct {
+	Wave       int
+	Objects    int
+	Applied    int
+	Reconciled int
+	Failed     int
+	// Skipped objects weren't applied, e.g. because the previous wave failed
+	Skipped int
+}
+
+// Phase summarizes the progress of the wave
+func (p WaveProgress) Phase() string {
+	switch {
+	case p.Failed > 0:
+		return "Failed"
+	case p.Skipped > 0 && p.Applied == 0:
+		return "Skipped"
+	case p.Reconciled == p.Objects:
+		return "Completed"
+	case p.Applied > 0:
+		return "InProgress"
+	default:
+		return "Pending"
+	}
+}
+
+// syncWaves holds the objects grouped by their wave
+type syncWaves struct {
+	waves    []int
+	objects  map[object.ObjMetadata]int
+	progress map[int]*WaveProgress
+}
+
+// orderSyncWaves groups the objects by their wave and makes the objects of each wave depend on the objects of the previous wave.
+// It returns nil if none of the objects sets the wave.
+func orderSyncWaves(objects object.UnstructuredSet) (*syncWaves, error) {
+	waves := &syncWaves{
+		objects:  map[object.ObjMetadata]int{},
+		progress: map[int]*WaveProgress{},
+	}
+	annotated := false
+	members := map[int][]object.ObjMetadata{}
+	for _, obj := range objects {
+		wave := 0
+		if value, ok := obj.GetAnnotations()[SyncWaveAnnotation]; ok {
+			var err error
+			wave, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sync wave %q of %s: %v", value, object.UnstructuredToObjMetadata(obj), err)
+			}
+			annotated = true
+		}
+		id := object.UnstructuredToObjMetadata(obj)
+		if _, ok := members[wave]; !ok {
+			waves.waves = append(waves.waves, wave)
+			waves.progress[wave] = &WaveProgress{Wave: wave}
+		}
+		members[wave] = append(members[wave], id)
+		waves.objects[id] = wave
+		waves.progress[wave].Objects++
+	}
+	if !annotated {
+		return nil, nil
+	}
+	sort.Ints(waves.waves)
+
+	for _, obj := range objects {
+		wave := waves.objects[object.UnstructuredToObjMetadata(obj)]
+		i := sort.SearchInts(waves.waves, wave)
+		if i == 0 {
+			continue
+		}
+		dependencies, err := dependson.ReadAnnotation(obj)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range members[waves.waves[i-1]] {
+			if !object.ObjMetadataSet(dependencies).Contains(id) {
+				dependencies = append(dependencies, id)
+			}
+		}
+		if err := dependson.WriteAnnotation(obj, dependencies); err != nil {
+			return nil, err
+		}
+	}
+	return waves, nil
+}
+
+// handle updates the progress of the waves with the event, returning whether the progress changed
+func (w *syncWaves) handle(e event.Event) bool {
+	var id object.ObjMetadata
+	var applied, reconciled, failed, skipped bool
+	switch e.Type {
+	case event.ApplyType:
+		id = e.ApplyEvent.Identifier
+		applied = e.ApplyEvent.Status == event.ApplySuccessful
+		failed = e.ApplyEvent.Status == event.ApplyFailed
+		skipped = e.ApplyEvent.Status == event.ApplySkipped
+	case event.WaitType:
+		id = e.WaitEvent.Identifier
+		reconciled = e.WaitEvent.Status == event.ReconcileSuccessful
+		failed = e.WaitEvent.Status == event.ReconcileFailed || e.WaitEvent.Status == event.ReconcileTimeout
+	default:
+		return false
+	}
+	wave, ok := w.objects[id]
+	if !ok || !(applied || reconciled || failed || skipped) {
+		return false
+	}
+
+	progress := w.progress[wave]
+	switch {
+	case applied:
+		progress.Applied++
+	case reconciled:
+		progress.Reconciled++
+	case failed:
+		progress.Failed++
+	case skipped:
+		progress.Skipped++
+	}
+	return true
+}
+
+// Progress returns the progress of the waves in the order they are applied
+func (w *syncWaves) Progress() []WaveProgress {
+	progress := make([]WaveProgress, 0, len(w.waves))
+	for _, wave := range w.waves {
+		progress = append(progress, *w.progress[wave])
+	}
+	return progress
+}
+
+// track passes the events through while updating the progress of the waves
+func (w *syncWaves) track(events <-chan event.Event, onProgress func([]WaveProgress)) <-chan event.Event {
+	tracked := make(chan event.Event)
+	go func() {
+		defer close(tracked)
+		onProgress(w.Progress())
+		for e := range events {
+			if w.handle(e) {
+				onProgress(w.Progress())
+			}
+			tracked <- e
+		}
+	}()
+	return tracked
+}
+
+// WaveStatuses converts the progress of the waves to the status of the Live
+func WaveStatuses(progress []WaveProgress) []kuberikv1alpha1.LiveWaveStatus {
+	if progress == nil {
+		return nil
+	}
+	status := []kuberikv1alpha1.LiveWaveStatus{}
+	for _, p := range progress {
+		status = append(status, kuberikv1alpha1.LiveWaveStatus{
+			Wave:       p.Wave,
+			Phase:      p.Phase(),
+			Objects:    p.Objects,
+			Applied:    p.Applied,
+			Reconciled: p.Reconciled,
+			Failed:     p.Failed,
+			Skipped:    p.Skipped,
+		})
+	}
+	return status
+}
@@ -0,0 +1,114 @@
+package live
+
+import (
+	"testing"
+
+	kuberikv1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/dependson"
+)
+
+func waveObject(kind, name string, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func TestOrderSyncWaves(t *testing.T) {
+	waves, err := orderSyncWaves(object.UnstructuredSet{
+		waveObject("ConfigMap", "a", nil),
+		waveObject("ConfigMap", "b", nil),
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, waves == nil)
+
+	_, err = orderSyncWaves(object.UnstructuredSet{
+		waveObject("ConfigMap", "a", map[string]string{SyncWaveAnnotation: "first"}),
+	})
+	assert.ErrorContains(t, err, `invalid sync wave "first"`)
+
+	namespace := waveObject("Namespace", "app", map[string]string{SyncWaveAnnotation: "-1"})
+	namespace.SetNamespace("")
+	config := waveObject("ConfigMap", "config", nil)
+	secret := waveObject("Secret", "secret", nil)
+	migrate := waveObject("Pod", "migrate", map[string]string{SyncWaveAnnotation: "5"})
+	server := waveObject("Pod", "server", map[string]string{
+		SyncWaveAnnotation:   "10",
+		dependson.Annotation: "/namespaces/default/Secret/secret",
+	})
+	objects := object.UnstructuredSet{server, config, migrate, namespace, secret}
+	waves, err = orderSyncWaves(objects)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, waves.Progress(), []WaveProgress{
+		{Wave: -1, Objects: 1},
+		{Wave: 0, Objects: 2},
+		{Wave: 5, Objects: 1},
+		{Wave: 10, Objects: 1},
+	})
+
+	dependencies := func(obj *unstructured.Unstructured) dependson.DependencySet {
+		deps, err := dependson.ReadAnnotation(obj)
+		assert.NilError(t, err)
+		return deps
+	}
+	assert.Assert(t, !dependson.HasAnnotation(namespace))
+	assert.DeepEqual(t, dependencies(config), dependson.DependencySet{object.UnstructuredToObjMetadata(namespace)})
+	assert.DeepEqual(t, dependencies(secret), dependson.DependencySet{object.UnstructuredToObjMetadata(namespace)})
+	assert.DeepEqual(t, dependencies(migrate), dependson.DependencySet{
+		object.UnstructuredToObjMetadata(config),
+		object.UnstructuredToObjMetadata(secret),
+	})
+	assert.DeepEqual(t, dependencies(server), dependson.DependencySet{
+		object.UnstructuredToObjMetadata(secret),
+		object.UnstructuredToObjMetadata(migrate),
+	})
+}
+
+func TestSyncWavesProgress(t *testing.T) {
+	config := waveObject("ConfigMap", "config", nil)
+	server := waveObject("Pod", "server", map[string]string{SyncWaveAnnotation: "1"})
+	waves, err := orderSyncWaves(object.UnstructuredSet{config, server})
+	assert.NilError(t, err)
+
+	events := make(chan event.Event, 4)
+	events <- event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: object.UnstructuredToObjMetadata(config), Status: event.ApplyPending}}
+	events <- event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: object.UnstructuredToObjMetadata(config), Status: event.ApplySuccessful}}
+	events <- event.Event{Type: event.WaitType, WaitEvent: event.WaitEvent{Identifier: object.UnstructuredToObjMetadata(config), Status: event.ReconcileTimeout}}
+	events <- event.Event{Type: event.ApplyType, ApplyEvent: event.ApplyEvent{Identifier: object.UnstructuredToObjMetadata(server), Status: event.ApplySkipped}}
+	close(events)
+
+	var progress [][]WaveProgress
+	forwarded := 0
+	for range waves.track(events, func(p []WaveProgress) { progress = append(progress, p) }) {
+		forwarded++
+	}
+	assert.Equal(t, forwarded, 4)
+	assert.DeepEqual(t, progress, [][]WaveProgress{
+		{{Wave: 0, Objects: 1}, {Wave: 1, Objects: 1}},
+		{{Wave: 0, Objects: 1, Applied: 1}, {Wave: 1, Objects: 1}},
+		{{Wave: 0, Objects: 1, Applied: 1, Failed: 1}, {Wave: 1, Objects: 1}},
+		{{Wave: 0, Objects: 1, Applied: 1, Failed: 1}, {Wave: 1, Objects: 1, Skipped: 1}},
+	})
+
+	assert.DeepEqual(t, WaveStatuses(progress[len(progress)-1]), []kuberikv1alpha1.LiveWaveStatus{
+		{Wave: 0, Phase: "Failed", Objects: 1, Applied: 1, Failed: 1},
+		{Wave: 1, Phase: "Skipped", Objects: 1, Skipped: 1},
+	})
+	assert.Assert(t, WaveStatuses(nil) == nil)
+}
+
+func TestWaveProgressPhase(t *testing.T) {
+	assert.Equal(t, WaveProgress{Objects: 2}.Phase(), "Pending")
+	assert.Equal(t, WaveProgress{Objects: 2, Applied: 1}.Phase(), "InProgress")
+	assert.Equal(t, WaveProgress{Objects: 2, Applied: 2, Reconciled: 1}.Phase(), "InProgress")
+	assert.Equal(t, WaveProgress{Objects: 2, Applied: 2, Reconciled: 2}.Phase(), "Completed")
+	assert.Equal(t, WaveProgress{Objects: 2, Applied: 1, Failed: 1}.Phase(), "Failed")
+	assert.Equal(t, WaveProgress{Objects: 2, Skipped: 2}.Phase(), "Skipped")
+}
@@ -0,0 +1,145 @@
+package receiver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var (
+	// ErrUnsupportedEvent is returned for valid payloads of events which don't trigger a refresh (e.g. ping)
+	ErrUnsupportedEvent = errors.New("unsupported event")
+	// ErrInvalidSignature is returned when the payload signature doesn't match the configured secret
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// PushEvent is a push to a branch or a tag of a git repository
+type PushEvent struct {
+	// URLs under which the pushed repository is reachable
+	RepositoryURLs []string
+	// Name of the pushed branch, empty if a tag was pushed
+	Branch string
+	// Name of the pushed tag, empty if a branch was pushed
+	Tag string
+}
+
+type githubPushPayload struct {
+	Ref        string `json:"ref"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+		GitURL   string `json:"git_url"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+type gitlabPushPayload struct {
+	Ref     string `json:"ref"`
+	Project struct {
+		GitHTTPURL string `json:"git_http_url"`
+		GitSSHURL  string `json:"git_ssh_url"`
+		WebURL     string `json:"web_url"`
+	} `json:"project"`
+}
+
+// ParsePushEvent verifies the payload of a GitHub, GitLab or Gitea webhook request
+// using the secret and parses the push event from it. Payloads are never valid for an empty secret.
+func ParsePushEvent(header http.Header, body []byte, secret []byte) (*PushEvent, error) {
+	if len(secret) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		if !validSHA256Signature(body, secret, header.Get("X-Gitea-Signature")) {
+			return nil, ErrInvalidSignature
+		}
+		if header.Get("X-Gitea-Event") != "push" {
+			return nil, ErrUnsupportedEvent
+		}
+		return parseGitHubPushPayload(body)
+	case header.Get("X-GitHub-Event") != "":
+		signature := header.Get("X-Hub-Signature-256")
+		if !strings.HasPrefix(signature, "sha256=") || !validSHA256Signature(body, secret, strings.TrimPrefix(signature, "sha256=")) {
+			return nil, ErrInvalidSignature
+		}
+		if header.Get("X-GitHub-Event") != "push" {
+			return nil, ErrUnsupportedEvent
+		}
+		return parseGitHubPushPayload(body)
+	case header.Get("X-Gitlab-Event") != "":
+		// GitLab doesn't sign the payload, but sends the configured secret token as is
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), secret) != 1 {
+			return nil, ErrInvalidSignature
+		}
+		// Pushes of tags are sent as a separate event, its payload is the same as the one of the branch pushes
+		if gitlabEvent := header.Get("X-Gitlab-Event"); gitlabEvent != "Push Hook" && gitlabEvent != "Tag Push Hook" {
+			return nil, ErrUnsupportedEvent
+		}
+		return parseGitLabPushPayload(body)
+	}
+	return nil, fmt.Errorf("unknown webhook provider")
+}
+
+func validSHA256Signature(body, secret []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func parseGitHubPushPayload(body []byte) (*PushEvent, error) {
+	payload := githubPushPayload{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return newPushEvent(payload.Ref,
+		payload.Repository.CloneURL,
+		payload.Repository.SSHURL,
+		payload.Repository.GitURL,
+		payload.Repository.HTMLURL,
+	)
+}
+
+func parseGitLabPushPayload(body []byte) (*PushEvent, error) {
+	payload := gitlabPushPayload{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	return newPushEvent(payload.Ref,
+		payload.Project.GitHTTPURL,
+		payload.Project.GitSSHURL,
+		payload.Project.WebURL,
+	)
+}
+
+func newPushEvent(ref string, urls ...string) (*PushEvent, error) {
+	event := &PushEvent{}
+	switch refName := plumbing.ReferenceName(ref); {
+	case refName.IsBranch():
+		event.Branch = refName.Short()
+	case refName.IsTag():
+		event.Tag = refName.Short()
+	default:
+		return nil, ErrUnsupportedEvent
+	}
+	for _, url := range urls {
+		if url != "" {
+			event.RepositoryURLs = append(event.RepositoryURLs, url)
+		}
+	}
+	if len(event.RepositoryURLs) == 0 {
+		return nil, fmt.Errorf("missing repository URL in the payload")
+	}
+	return event, nil
+}
@@ -0,0 +1,197 @@
+package receiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+	"github.com/kuberik/kuberik/pkg/repository"
+)
+
+const (
+	// SecretTokenField is the field of the Secret holding the token used to verify the webhook payloads
+	SecretTokenField = "token"
+
+	maxPayloadSize = 25 << 20
+)
+
+// Receiver is receiving push webhooks from git providers and triggers
+// reconciliation of LiveDeployments and LiveDeploymentGroups deploying the pushed branch or tag.
+type Receiver struct {
+	Client client.Client
+	// BindAddress is the address the receiver listens on
+	BindAddress string
+	// SecretRef references the Secret holding the token used to verify the webhook payloads
+	SecretRef types.NamespacedName
+
+	// LiveDeploymentEvents receives LiveDeployments deploying the pushed branch or tag
+	LiveDeploymentEvents chan<- event.GenericEvent
+	// LiveDeploymentGroupEvents receives LiveDeploymentGroups deploying from the pushed repository
+	LiveDeploymentGroupEvents chan<- event.GenericEvent
+}
+
+// Start starts serving the webhooks until the context is done
+func (r *Receiver) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              r.BindAddress,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.FromContext(ctx).Info("starting push webhook receiver", "address", r.BindAddress)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection makes sure that the receiver runs only alongside the controllers consuming its events
+func (r *Receiver) NeedLeaderElection() bool {
+	return true
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	logger := log.FromContext(ctx).WithName("receiver")
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, r.SecretRef, secret); err != nil {
+		logger.Error(err, "failed to get webhook secret", "secret", r.SecretRef)
+		http.Error(w, "failed to get webhook secret", http.StatusInternalServerError)
+		return
+	}
+	token := secret.Data[SecretTokenField]
+	if len(token) == 0 {
+		// Any request would be accepted as valid without a token
+		logger.Error(fmt.Errorf("missing %s field", SecretTokenField), "invalid webhook secret", "secret", r.SecretRef)
+		http.Error(w, "invalid webhook secret", http.StatusInternalServerError)
+		return
+	}
+
+	pushEvent, err := ParsePushEvent(req.Header, body, token)
+	switch {
+	case errors.Is(err, ErrInvalidSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.Is(err, ErrUnsupportedEvent):
+		w.WriteHeader(http.StatusAccepted)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	triggered, err := r.trigger(ctx, pushEvent)
+	if err != nil {
+		logger.Error(err, "failed to trigger reconciliation", "branch", pushEvent.Branch, "tag", pushEvent.Tag, "urls", pushEvent.RepositoryURLs)
+		http.Error(w, "failed to trigger reconciliation", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("received push", "branch", pushEvent.Branch, "tag", pushEvent.Tag, "urls", pushEvent.RepositoryURLs, "triggered", triggered)
+	fmt.Fprintf(w, "triggered %d resources\n", triggered)
+}
+
+// trigger enqueues the LiveDeployments and LiveDeploymentGroups affected by the push
+func (r *Receiver) trigger(ctx context.Context, pushEvent *PushEvent) (int, error) {
+	repoURLs := map[string]bool{}
+	for _, url := range pushEvent.RepositoryURLs {
+		repoURLs[repository.NormalizeURL(url)] = true
+	}
+	triggered := 0
+
+	liveDeployments := &kuberikiov1alpha1.LiveDeploymentList{}
+	if err := r.Client.List(ctx, liveDeployments); err != nil {
+		return triggered, err
+	}
+	for i := range liveDeployments.Items {
+		ld := &liveDeployments.Items[i]
+		if ld.Spec.Template == nil || !repoURLs[repository.NormalizeURL(ld.Spec.Template.Spec.Repository.URL)] {
+			continue
+		}
+		if !deploysPushedRef(ld, pushEvent) {
+			continue
+		}
+		if err := send(ctx, r.LiveDeploymentEvents, ld); err != nil {
+			return triggered, err
+		}
+		triggered++
+	}
+
+	liveDeploymentGroups := &kuberikiov1alpha1.LiveDeploymentGroupList{}
+	if err := r.Client.List(ctx, liveDeploymentGroups); err != nil {
+		return triggered, err
+	}
+	for i := range liveDeploymentGroups.Items {
+		ldg := &liveDeploymentGroups.Items[i]
+		if ldg.Spec.Template == nil || !repoURLs[repository.NormalizeURL(ldg.Spec.Template.Spec.Repository.URL)] {
+			continue
+		}
+		if pushEvent.Branch == "" {
+			continue
+		}
+		if matcher, err := regexp.Compile(ldg.Spec.BranchMatch); err != nil || !matcher.MatchString(pushEvent.Branch) {
+			continue
+		}
+		if err := send(ctx, r.LiveDeploymentGroupEvents, ldg); err != nil {
+			return triggered, err
+		}
+		triggered++
+	}
+
+	return triggered, nil
+}
+
+// deploysPushedRef returns whether the LiveDeployment deploys the pushed branch or tag
+func deploysPushedRef(ld *kuberikiov1alpha1.LiveDeployment, pushEvent *PushEvent) bool {
+	if ld.Spec.Tag == nil {
+		return pushEvent.Branch != "" && ld.Spec.Branch == pushEvent.Branch
+	}
+	if pushEvent.Tag == "" {
+		return false
+	}
+	if ld.Spec.Tag.Semver != "" {
+		_, err := repository.SelectSemverTag([]string{pushEvent.Tag}, ld.Spec.Tag.Semver)
+		return err == nil
+	}
+	return ld.Spec.Tag.Name == pushEvent.Tag
+}
+
+func send(ctx context.Context, events chan<- event.GenericEvent, obj client.Object) error {
+	if events == nil {
+		return nil
+	}
+	select {
+	case events <- event.GenericEvent{Object: obj}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,362 @@
+package receiver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	kuberikiov1alpha1 "github.com/kuberik/kuberik/api/v1alpha1"
+)
+
+const (
+	testSecret = "webhook-secret"
+
+	githubPushBody = `{
+	"ref": "refs/heads/main",
+	"repository": {
+		"clone_url": "https://github.com/kuberik/example.git",
+		"ssh_url": "git@github.com:kuberik/example.git",
+		"html_url": "https://github.com/kuberik/example"
+	}
+}`
+	githubTagPushBody = `{
+	"ref": "refs/tags/v1.0.0",
+	"repository": {
+		"clone_url": "https://github.com/kuberik/example.git"
+	}
+}`
+	gitlabPushBody = `{
+	"ref": "refs/heads/main",
+	"project": {
+		"git_http_url": "https://gitlab.com/kuberik/example.git",
+		"git_ssh_url": "git@gitlab.com:kuberik/example.git",
+		"web_url": "https://gitlab.com/kuberik/example"
+	}
+}`
+	gitlabTagPushBody = `{
+	"ref": "refs/tags/v1.2.0",
+	"project": {
+		"git_http_url": "https://gitlab.com/kuberik/example.git"
+	}
+}`
+)
+
+func sign(body string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestParsePushEvent(t *testing.T) {
+	githubEvent := &PushEvent{
+		Branch: "main",
+		RepositoryURLs: []string{
+			"https://github.com/kuberik/example.git",
+			"git@github.com:kuberik/example.git",
+			"https://github.com/kuberik/example",
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		header   http.Header
+		body     string
+		expected *PushEvent
+		err      error
+	}{{
+		name: "github",
+		header: http.Header{
+			"X-Github-Event":      {"push"},
+			"X-Hub-Signature-256": {"sha256=" + sign(githubPushBody, testSecret)},
+		},
+		body:     githubPushBody,
+		expected: githubEvent,
+	}, {
+		name: "github invalid signature",
+		header: http.Header{
+			"X-Github-Event":      {"push"},
+			"X-Hub-Signature-256": {"sha256=" + sign(githubPushBody, "other-secret")},
+		},
+		body: githubPushBody,
+		err:  ErrInvalidSignature,
+	}, {
+		name: "github missing signature",
+		header: http.Header{
+			"X-Github-Event": {"push"},
+		},
+		body: githubPushBody,
+		err:  ErrInvalidSignature,
+	}, {
+		name: "github ping",
+		header: http.Header{
+			"X-Github-Event":      {"ping"},
+			"X-Hub-Signature-256": {"sha256=" + sign(`{}`, testSecret)},
+		},
+		body: `{}`,
+		err:  ErrUnsupportedEvent,
+	}, {
+		name: "github tag",
+		header: http.Header{
+			"X-Github-Event":      {"push"},
+			"X-Hub-Signature-256": {"sha256=" + sign(githubTagPushBody, testSecret)},
+		},
+		body: githubTagPushBody,
+		expected: &PushEvent{
+			Tag:            "v1.0.0",
+			RepositoryURLs: []string{"https://github.com/kuberik/example.git"},
+		},
+	}, {
+		name: "github notes",
+		header: http.Header{
+			"X-Github-Event":      {"push"},
+			"X-Hub-Signature-256": {"sha256=" + sign(`{"ref": "refs/notes/commits"}`, testSecret)},
+		},
+		body: `{"ref": "refs/notes/commits"}`,
+		err:  ErrUnsupportedEvent,
+	}, {
+		name: "gitea",
+		header: http.Header{
+			"X-Gitea-Event":     {"push"},
+			"X-Gitea-Signature": {sign(githubPushBody, testSecret)},
+		},
+		body:     githubPushBody,
+		expected: githubEvent,
+	}, {
+		name: "gitea invalid signature",
+		header: http.Header{
+			"X-Gitea-Event":     {"push"},
+			"X-Gitea-Signature": {"invalid"},
+		},
+		body: githubPushBody,
+		err:  ErrInvalidSignature,
+	}, {
+		name: "gitlab",
+		header: http.Header{
+			"X-Gitlab-Event": {"Push Hook"},
+			"X-Gitlab-Token": {testSecret},
+		},
+		body: gitlabPushBody,
+		expected: &PushEvent{
+			Branch: "main",
+			RepositoryURLs: []string{
+				"https://gitlab.com/kuberik/example.git",
+				"git@gitlab.com:kuberik/example.git",
+				"https://gitlab.com/kuberik/example",
+			},
+		},
+	}, {
+		name: "gitlab tag",
+		header: http.Header{
+			"X-Gitlab-Event": {"Tag Push Hook"},
+			"X-Gitlab-Token": {testSecret},
+		},
+		body: gitlabTagPushBody,
+		expected: &PushEvent{
+			Tag:            "v1.2.0",
+			RepositoryURLs: []string{"https://gitlab.com/kuberik/example.git"},
+		},
+	}, {
+		name: "gitlab merge request",
+		header: http.Header{
+			"X-Gitlab-Event": {"Merge Request Hook"},
+			"X-Gitlab-Token": {testSecret},
+		},
+		body: gitlabPushBody,
+		err:  ErrUnsupportedEvent,
+	}, {
+		name: "gitlab invalid token",
+		header: http.Header{
+			"X-Gitlab-Event": {"Push Hook"},
+			"X-Gitlab-Token": {"other-secret"},
+		},
+		body: gitlabPushBody,
+		err:  ErrInvalidSignature,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := ParsePushEvent(tc.header, []byte(tc.body), []byte(testSecret))
+			if tc.err != nil {
+				assert.Assert(t, errors.Is(err, tc.err), "unexpected error: %v", err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.DeepEqual(t, event, tc.expected)
+		})
+	}
+
+	_, err := ParsePushEvent(http.Header{}, []byte(githubPushBody), []byte(testSecret))
+	assert.ErrorContains(t, err, "unknown webhook provider")
+
+	_, err = ParsePushEvent(http.Header{"X-Gitlab-Event": {"Push Hook"}}, []byte(gitlabPushBody), nil)
+	assert.Assert(t, errors.Is(err, ErrInvalidSignature), "empty secret should never be valid: %v", err)
+}
+
+func TestReceiver(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NilError(t, kuberikiov1alpha1.AddToScheme(scheme))
+
+	template := func(url string) *kuberikiov1alpha1.LiveTemplate {
+		return &kuberikiov1alpha1.LiveTemplate{
+			Spec: kuberikiov1alpha1.LiveSpec{
+				Repository: kuberikiov1alpha1.Repository{URL: url},
+			},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "receiver", Namespace: "kuberik-system"},
+			Data:       map[string][]byte{SecretTokenField: []byte(testSecret)},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "https-main", Namespace: "default"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentSpec{Branch: "main", Template: template("https://github.com/kuberik/example")},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "ssh-main", Namespace: "other"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentSpec{Branch: "main", Template: template("ssh://git@github.com/kuberik/example.git")},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "https-develop", Namespace: "default"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentSpec{Branch: "develop", Template: template("https://github.com/kuberik/example")},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-repo-main", Namespace: "default"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentSpec{Branch: "main", Template: template("https://github.com/kuberik/other")},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "tag-name", Namespace: "default"},
+			Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+				Tag:      &kuberikiov1alpha1.TagSelector{Name: "v1.0.0"},
+				Template: template("https://github.com/kuberik/example"),
+			},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "tag-semver", Namespace: "default"},
+			Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+				Branch:   "main",
+				Tag:      &kuberikiov1alpha1.TagSelector{Semver: "^1.0.0"},
+				Template: template("https://github.com/kuberik/example"),
+			},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "tag-semver-v2", Namespace: "default"},
+			Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+				Tag:      &kuberikiov1alpha1.TagSelector{Semver: ">=2.0.0"},
+				Template: template("https://github.com/kuberik/example"),
+			},
+		},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "gitlab-tag-semver", Namespace: "default"},
+			Spec: kuberikiov1alpha1.LiveDeploymentSpec{
+				Tag:      &kuberikiov1alpha1.TagSelector{Semver: "^1.0.0"},
+				Template: template("git@gitlab.com:kuberik/example.git"),
+			},
+		},
+		&kuberikiov1alpha1.LiveDeploymentGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "all", Namespace: "default"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentGroupSpec{Template: template("https://github.com/kuberik/example.git")},
+		},
+		&kuberikiov1alpha1.LiveDeploymentGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "features", Namespace: "default"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentGroupSpec{BranchMatch: "^feature/", Template: template("https://github.com/kuberik/example.git")},
+		},
+	).Build()
+
+	liveDeploymentEvents := make(chan event.GenericEvent, 10)
+	liveDeploymentGroupEvents := make(chan event.GenericEvent, 10)
+	receiver := &Receiver{
+		Client:                    client,
+		SecretRef:                 types.NamespacedName{Name: "receiver", Namespace: "kuberik-system"},
+		LiveDeploymentEvents:      liveDeploymentEvents,
+		LiveDeploymentGroupEvents: liveDeploymentGroupEvents,
+	}
+
+	post := func(header http.Header, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header = header
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		return rec
+	}
+	received := func(events chan event.GenericEvent) []string {
+		names := []string{}
+		for len(events) > 0 {
+			e := <-events
+			names = append(names, e.Object.GetNamespace()+"/"+e.Object.GetName())
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	rec := post(http.Header{
+		"X-Github-Event":      {"push"},
+		"X-Hub-Signature-256": {"sha256=" + sign(githubPushBody, testSecret)},
+	}, githubPushBody)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.DeepEqual(t, received(liveDeploymentEvents), []string{"default/https-main", "other/ssh-main"})
+	assert.DeepEqual(t, received(liveDeploymentGroupEvents), []string{"default/all"})
+
+	// Tag pushes trigger only the LiveDeployments deploying the tag
+	rec = post(http.Header{
+		"X-Github-Event":      {"push"},
+		"X-Hub-Signature-256": {"sha256=" + sign(githubTagPushBody, testSecret)},
+	}, githubTagPushBody)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.DeepEqual(t, received(liveDeploymentEvents), []string{"default/tag-name", "default/tag-semver"})
+	assert.DeepEqual(t, received(liveDeploymentGroupEvents), []string{})
+
+	rec = post(http.Header{
+		"X-Gitlab-Event": {"Tag Push Hook"},
+		"X-Gitlab-Token": {testSecret},
+	}, gitlabTagPushBody)
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.DeepEqual(t, received(liveDeploymentEvents), []string{"default/gitlab-tag-semver"})
+	assert.DeepEqual(t, received(liveDeploymentGroupEvents), []string{})
+
+	rec = post(http.Header{
+		"X-Github-Event":      {"push"},
+		"X-Hub-Signature-256": {"sha256=" + sign(githubPushBody, "other-secret")},
+	}, githubPushBody)
+	assert.Equal(t, rec.Code, http.StatusUnauthorized)
+	assert.Equal(t, len(liveDeploymentEvents)+len(liveDeploymentGroupEvents), 0)
+
+	rec = post(http.Header{
+		"X-Github-Event":      {"ping"},
+		"X-Hub-Signature-256": {"sha256=" + sign(`{}`, testSecret)},
+	}, `{}`)
+	assert.Equal(t, rec.Code, http.StatusAccepted)
+	assert.Equal(t, len(liveDeploymentEvents)+len(liveDeploymentGroupEvents), 0)
+
+	// Requests are refused if the secret has no token, since they couldn't be verified
+	empty := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "receiver", Namespace: "kuberik-system"}},
+		&kuberikiov1alpha1.LiveDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "https-main", Namespace: "default"},
+			Spec:       kuberikiov1alpha1.LiveDeploymentSpec{Branch: "main", Template: template("https://github.com/kuberik/example")},
+		},
+	).Build()
+	receiver.Client = empty
+	rec = post(http.Header{"X-Gitlab-Event": {"Push Hook"}}, gitlabPushBody)
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+	rec = post(http.Header{
+		"X-Github-Event":      {"push"},
+		"X-Hub-Signature-256": {"sha256=" + sign(githubPushBody, "")},
+	}, githubPushBody)
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+	assert.Equal(t, len(liveDeploymentEvents)+len(liveDeploymentGroupEvents), 0)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+const cacheKeySize = 16
+
+// Cache shares the git repositories between the reconcilers, so that each commit is fetched and checked out once.
+// The repositories are keyed by their normalized URL and the identity of the credentials used to access them,
+// so the objects fetched with some credentials are never served to the users of other credentials.
+type Cache struct {
+	dir string
+
+	mu    sync.Mutex
+	repos map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	mu   sync.Mutex
+	repo *GitRepository
+	// removed is set once the repository is garbage collected and the entry is no longer in the cache
+	removed bool
+}
+
+// NewCache creates a cache storing the repositories in the directory
+func NewCache(dir string) *Cache {
+	return &Cache{
+		dir:   dir,
+		repos: map[string]*cacheEntry{},
+	}
+}
+
+// cacheKey identifies the repository at the URL accessed with the credentials
+func cacheKey(url, credentials string) string {
+	sum := sha256.Sum256([]byte(remoteLocation(url) + "\n" + credentials))
+	return hex.EncodeToString(sum[:cacheKeySize])
+}
+
+func isCacheKey(name string) bool {
+	decoded, err := hex.DecodeString(name)
+	return err == nil && len(decoded) == cacheKeySize
+}
+
+// Open returns the repository at the URL accessed with the credentials, initializing it on first use.
+// credentials identifies the credentials of auth, e.g. the secret they were read from, and is empty for anonymous access.
+// The repository is locked for exclusive use until the returned release function is called.
+func (c *Cache) Open(url, credentials string, auth transport.AuthMethod, tlsConfig *TLSConfig) (*GitRepository, func(), error) {
+	key := cacheKey(url, credentials)
+	entry := c.lock(key)
+	if entry.repo == nil {
+		repo, err := InitGitRepository(path.Join(c.dir, key), url, auth, tlsConfig)
+		if err != nil {
+			entry.mu.Unlock()
+			return nil, nil, err
+		}
+		entry.repo = repo
+	}
+	// Credentials with the same identity can still change, e.g. by rotating the tokens
+	if err := entry.repo.setRemote(url, auth, tlsConfig); err != nil {
+		entry.mu.Unlock()
+		return nil, nil, err
+	}
+	return entry.repo, entry.mu.Unlock, nil
+}
+
+// lock locks the entry of the repository with the key for exclusive use
+func (c *Cache) lock(key string) *cacheEntry {
+	for {
+		c.mu.Lock()
+		entry, ok := c.repos[key]
+		if !ok {
+			entry = &cacheEntry{}
+			c.repos[key] = entry
+		}
+		c.mu.Unlock()
+
+		entry.mu.Lock()
+		// The entry could be removed while waiting for the lock, the repository then has a new entry
+		if !entry.removed {
+			return entry
+		}
+		entry.mu.Unlock()
+	}
+}
+
+// remove removes the locked entry of the repository with the key from the cache
+func (c *Cache) remove(key string, entry *cacheEntry) {
+	c.mu.Lock()
+	delete(c.repos, key)
+	c.mu.Unlock()
+	entry.removed = true
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	fixtures "github.com/go-git/go-git-fixtures/v4"
+	"gotest.tools/v3/assert"
+)
+
+func TestCacheKey(t *testing.T) {
+	assert.Equal(t, cacheKey("https://github.com/kuberik/kuberik.git", ""), cacheKey("https://GitHub.com:443/kuberik/kuberik", ""))
+	assert.Equal(t, cacheKey("git@github.com:kuberik/kuberik", "default/auth"), cacheKey("ssh://git@github.com/kuberik/kuberik.git", "default/auth"))
+	// Repositories fetched from different servers never share the cache
+	assert.Assert(t, cacheKey("https://github.com/kuberik/kuberik", "default/auth") != cacheKey("ssh://git@github.com/kuberik/kuberik", "default/auth"))
+	assert.Assert(t, cacheKey("https://github.com/kuberik/kuberik", "") != cacheKey("https://github.com:8443/kuberik/kuberik", ""))
+	assert.Assert(t, cacheKey("https://github.com/kuberik/kuberik", "") != cacheKey("http://github.com/kuberik/kuberik", ""))
+	assert.Assert(t, cacheKey("https://github.com/kuberik/kuberik", "") != cacheKey("https://github.com/kuberik/kuberik", "default/auth"))
+	assert.Assert(t, cacheKey("https://github.com/kuberik/kuberik", "default/auth") != cacheKey("https://github.com/kuberik/kuberik", "other/auth"))
+	assert.Assert(t, cacheKey("https://github.com/kuberik/kuberik", "") != cacheKey("https://github.com/kuberik/other", ""))
+}
+
+func TestCacheOpen(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	repoURL := fixtures.Basic().One().DotGit().Root()
+
+	repo, release, err := cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	release()
+
+	shared, release, err := cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	release()
+	assert.Assert(t, repo == shared, "repository should be shared")
+
+	other, release, err := cache.Open(repoURL, "default/auth", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	release()
+	assert.Assert(t, repo != other, "repositories accessed with different credentials should not be shared")
+	assert.Assert(t, repo.root != other.root, "repositories accessed with different credentials should not share a directory")
+
+	// Reopening the cache should reuse the repositories on disk
+	reopened, release, err := NewCache(cache.dir).Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to reopen repository")
+	release()
+	assert.Equal(t, reopened.root, repo.root)
+}
+
+func TestCacheLock(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	repoURL := fixtures.Basic().One().DotGit().Root()
+
+	_, release, err := cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+
+	opened := make(chan struct{})
+	go func() {
+		_, release, err := cache.Open(repoURL, "", nil, nil)
+		if err == nil {
+			release()
+		}
+		close(opened)
+	}()
+
+	select {
+	case <-opened:
+		t.Fatal("repository should be locked until released")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Other repositories aren't locked
+	_, releaseOther, err := cache.Open(repoURL, "default/auth", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	releaseOther()
+
+	release()
+	select {
+	case <-opened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("repository should be opened once released")
+	}
+}
+
+func TestCacheLockRemoved(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	key := cacheKey("https://github.com/kuberik/kuberik.git", "")
+	entry := cache.lock(key)
+
+	locked := make(chan *cacheEntry)
+	go func() {
+		entry := cache.lock(key)
+		entry.mu.Unlock()
+		locked <- entry
+	}()
+
+	// The repository is garbage collected while the other user waits for it
+	time.Sleep(100 * time.Millisecond)
+	cache.remove(key, entry)
+	entry.mu.Unlock()
+
+	select {
+	case newEntry := <-locked:
+		assert.Assert(t, newEntry != entry, "removed entry should not be used")
+		assert.Assert(t, cache.repos[key] == newEntry, "new entry should be cached")
+	case <-time.After(5 * time.Second):
+		t.Fatal("repository should be locked once the removed entry is released")
+	}
+}
+
+func TestCacheCommitDir(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	repoURL := fixtures.Basic().One().DotGit().Root()
+
+	repo, release, err := cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	defer release()
+
+	commit, err := repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+	commitDir, err := repo.CreateCommitDir(*commit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+
+	// Commits are checked out only once
+	assert.NilError(t, os.WriteFile(path.Join(commitDir, "marker"), nil, 0644))
+	sameCommitDir, err := repo.CreateCommitDir(*commit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	assert.Equal(t, sameCommitDir, commitDir)
+	_, err = os.Stat(path.Join(commitDir, "marker"))
+	assert.NilError(t, err, "commit should not be checked out again")
+
+	entries, err := os.ReadDir(path.Dir(commitDir))
+	assert.NilError(t, err, "failed to read commits dir")
+	assert.Equal(t, len(entries), 1, "no partial checkouts should be left behind")
+}
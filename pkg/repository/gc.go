@@ -0,0 +1,468 @@
+package repository
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/idxfile"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Reference marks a repository as in use, along with the commit in use.
+// Commit is empty if only the repository itself is in use, e.g. to poll its branches.
+type Reference struct {
+	URL string
+	// Credentials identifies the credentials used to access the repository, see Cache.Open
+	Credentials string
+	Commit      string
+}
+
+// Usage is the disk space used by the cached repositories
+type Usage struct {
+	Repositories int
+	Commits      int
+	// ObjectBytes is the size of the git objects of the repositories
+	ObjectBytes int64
+	// CommitBytes is the size of the checked out commits
+	CommitBytes int64
+}
+
+// GarbageCollection is the outcome of collecting the garbage of the cache
+type GarbageCollection struct {
+	RemovedRepositories int
+	RemovedCommits      int
+	// PrunedObjects is the number of unreachable git objects removed from the repositories which are kept
+	PrunedObjects int
+	// Usage is the disk usage remaining after the collection
+	Usage Usage
+}
+
+// GarbageCollect removes the repositories which aren't referenced and the checked out commits which aren't referenced,
+// except for the keepRecentCommits most recently used commits of each repository. The git objects of the kept
+// repositories and of their submodules which are only reachable from the commits which aren't referenced are pruned.
+// Repositories in use are not collected until they are released.
+func (c *Cache) GarbageCollect(references []Reference, keepRecentCommits int) (GarbageCollection, error) {
+	referenced := map[string]map[string]bool{}
+	for _, ref := range references {
+		key := cacheKey(ref.URL, ref.Credentials)
+		if _, ok := referenced[key]; !ok {
+			referenced[key] = map[string]bool{}
+		}
+		if ref.Commit != "" {
+			referenced[key][ref.Commit] = true
+		}
+	}
+
+	result := GarbageCollection{}
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	for _, e := range entries {
+		// The directory can be shared with other files, e.g. lost+found of a persistent volume
+		key := e.Name()
+		if !e.IsDir() || !isCacheKey(key) {
+			continue
+		}
+		err := func() error {
+			entry := c.lock(key)
+			defer entry.mu.Unlock()
+
+			dir := path.Join(c.dir, key)
+			commits, ok := referenced[key]
+			if !ok {
+				entry.repo = nil
+				if err := os.RemoveAll(dir); err != nil {
+					return err
+				}
+				c.remove(key, entry)
+				result.RemovedRepositories++
+				return nil
+			}
+
+			removed, err := garbageCollectCommits(path.Join(dir, commitsDir), commits, keepRecentCommits)
+			result.RemovedCommits += removed
+			if err != nil {
+				return err
+			}
+
+			// The objects are changed under the opened repository, so it's opened again on the next use
+			entry.repo = nil
+			pruned, err := pruneRepositories(dir, commits)
+			result.PrunedObjects += pruned
+			if err != nil {
+				return err
+			}
+			return addUsage(&result.Usage, dir)
+		}()
+		if err != nil {
+			return result, err
+		}
+	}
+
+	recordGarbageCollection(result)
+	return result, nil
+}
+
+// garbageCollectCommits removes the checked out commits which aren't referenced, except for the keepRecent most recently used ones.
+// Leftovers of interrupted checkouts are always removed.
+func garbageCollectCommits(dir string, referenced map[string]bool, keepRecent int) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	type commitDir struct {
+		name    string
+		modTime time.Time
+	}
+	var unreferenced []commitDir
+	var remove []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), checkoutDirPrefix) {
+			remove = append(remove, e.Name())
+			continue
+		}
+		// Checkouts with options are suffixed, see CheckoutOptions
+		if referenced[strings.SplitN(e.Name(), "-", 2)[0]] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return 0, err
+		}
+		unreferenced = append(unreferenced, commitDir{name: e.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(unreferenced, func(i, j int) bool {
+		return unreferenced[i].modTime.After(unreferenced[j].modTime)
+	})
+
+	removed := 0
+	for i, commit := range unreferenced {
+		if i >= keepRecent {
+			remove = append(remove, commit.name)
+			removed++
+		}
+	}
+	for _, name := range remove {
+		if err := os.RemoveAll(path.Join(dir, name)); err != nil {
+			return 0, err
+		}
+	}
+	return removed, nil
+}
+
+// pruneRepositories prunes the objects of the repository in the directory and of its submodules.
+// The commits of the submodules aren't referenced directly, so they're fetched again when needed.
+func pruneRepositories(dir string, referencedCommits map[string]bool) (int, error) {
+	pruned, err := pruneObjects(path.Join(dir, repoDirName), referencedCommits)
+	if err != nil {
+		return pruned, err
+	}
+
+	modules, err := os.ReadDir(path.Join(dir, modulesDir))
+	if os.IsNotExist(err) {
+		return pruned, nil
+	}
+	if err != nil {
+		return pruned, err
+	}
+	for _, module := range modules {
+		prunedModule, err := pruneObjects(path.Join(dir, modulesDir, module.Name(), repoDirName), nil)
+		pruned += prunedModule
+		if err != nil {
+			return pruned, err
+		}
+	}
+	return pruned, nil
+}
+
+// pruneObjects removes the objects of the repository in the directory which are neither reachable from its references
+// nor from the referenced commits. The references to the fetched commits which aren't referenced are removed first.
+// Objects are reachable up to the shallow commits and the commits of the submodules aren't followed.
+func pruneObjects(repoDir string, referencedCommits map[string]bool) (int, error) {
+	r, err := git.PlainOpen(repoDir)
+	if err == git.ErrRepositoryNotExists {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	roots, err := pruneFetchedCommitReferences(r, referencedCommits)
+	if err != nil {
+		return 0, err
+	}
+	for commit := range referencedCommits {
+		roots = append(roots, plumbing.NewHash(commit))
+	}
+	reachable, err := reachableObjects(r, roots)
+	if err != nil {
+		return 0, err
+	}
+
+	pos, ok := r.Storer.(storer.PackedObjectStorer)
+	if !ok {
+		return 0, git.ErrPackedObjectsNotSupported
+	}
+	los, ok := r.Storer.(storer.LooseObjectStorer)
+	if !ok {
+		return 0, git.ErrLooseObjectsNotSupported
+	}
+
+	unreachable := map[plumbing.Hash]bool{}
+	packs, err := pos.ObjectPacks()
+	if err != nil {
+		return 0, err
+	}
+	for _, pack := range packs {
+		if err := forEachPackedObject(repoDir, pack, func(hash plumbing.Hash) {
+			if !reachable[hash] {
+				unreachable[hash] = true
+			}
+		}); err != nil {
+			return 0, err
+		}
+	}
+	// Packs only contain unreachable objects if they're repacked, the same as git does
+	if len(unreachable) > 0 {
+		if err := repackObjects(r, pos, packs, reachable); err != nil {
+			return 0, err
+		}
+	}
+
+	err = los.ForEachObjectHash(func(hash plumbing.Hash) error {
+		if reachable[hash] {
+			return nil
+		}
+		unreachable[hash] = true
+		return los.DeleteLooseObject(hash)
+	})
+	if err != nil {
+		return len(unreachable), err
+	}
+
+	shallow, err := r.Storer.Shallow()
+	if err != nil {
+		return len(unreachable), err
+	}
+	var keptShallow []plumbing.Hash
+	for _, hash := range shallow {
+		if reachable[hash] {
+			keptShallow = append(keptShallow, hash)
+		}
+	}
+	if len(keptShallow) != len(shallow) {
+		err = r.Storer.SetShallow(keptShallow)
+	}
+	return len(unreachable), err
+}
+
+// pruneFetchedCommitReferences removes the references to the fetched commits which aren't referenced
+// and returns the hashes of the remaining references
+func pruneFetchedCommitReferences(r *git.Repository, referencedCommits map[string]bool) ([]plumbing.Hash, error) {
+	refs, err := r.Storer.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+	defer refs.Close()
+
+	var hashes []plumbing.Hash
+	prefix := fetchedCommitReferenceName("").String()
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		name := ref.Name().String()
+		if strings.HasPrefix(name, prefix) && !referencedCommits[strings.TrimPrefix(name, prefix)] {
+			return r.Storer.RemoveReference(ref.Name())
+		}
+		hashes = append(hashes, ref.Hash())
+		return nil
+	})
+	return hashes, err
+}
+
+// reachableObjects walks the objects reachable from the roots. Objects which aren't in the repository,
+// e.g. the parents of the shallow commits, are skipped.
+func reachableObjects(r *git.Repository, roots []plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	reachable := map[plumbing.Hash]bool{}
+	pending := append([]plumbing.Hash{}, roots...)
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if reachable[hash] {
+			continue
+		}
+
+		obj, err := object.GetObject(r.Storer, hash)
+		if err == plumbing.ErrObjectNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reachable[hash] = true
+
+		switch obj := obj.(type) {
+		case *object.Commit:
+			pending = append(pending, obj.TreeHash)
+			pending = append(pending, obj.ParentHashes...)
+		case *object.Tree:
+			for _, entry := range obj.Entries {
+				if entry.Mode != filemode.Submodule {
+					pending = append(pending, entry.Hash)
+				}
+			}
+		case *object.Tag:
+			pending = append(pending, obj.Target)
+		}
+	}
+	return reachable, nil
+}
+
+// forEachPackedObject calls the function with the hash of each object in the pack
+func forEachPackedObject(repoDir string, pack plumbing.Hash, fn func(plumbing.Hash)) error {
+	f, err := os.Open(path.Join(repoDir, "objects", "pack", fmt.Sprintf("pack-%s.idx", pack)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	idx := idxfile.NewMemoryIndex()
+	if err := idxfile.NewDecoder(f).Decode(idx); err != nil {
+		return err
+	}
+	entries, err := idx.Entries()
+	if err != nil {
+		return err
+	}
+	defer entries.Close()
+	for {
+		entry, err := entries.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn(entry.Hash)
+	}
+}
+
+// repackObjects replaces the packs with a single pack of the reachable objects
+func repackObjects(r *git.Repository, pos storer.PackedObjectStorer, packs []plumbing.Hash, reachable map[plumbing.Hash]bool) error {
+	newPack := plumbing.ZeroHash
+	if len(reachable) > 0 {
+		var err error
+		if newPack, err = writePack(r, reachable); err != nil {
+			return err
+		}
+	}
+	for _, pack := range packs {
+		if pack == newPack {
+			continue
+		}
+		if err := pos.DeleteOldObjectPackAndIndex(pack, time.Time{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePack(r *git.Repository, objects map[plumbing.Hash]bool) (plumbing.Hash, error) {
+	cfg, err := r.Config()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	pfw, ok := r.Storer.(storer.PackfileWriter)
+	if !ok {
+		return plumbing.ZeroHash, fmt.Errorf("repository storer is not a packfile writer")
+	}
+
+	hashes := make([]plumbing.Hash, 0, len(objects))
+	for hash := range objects {
+		hashes = append(hashes, hash)
+	}
+	w, err := pfw.PackfileWriter()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	hash, err := packfile.NewEncoder(w, r.Storer, false).Encode(hashes, cfg.Pack.Window)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return hash, err
+}
+
+// addUsage adds the disk usage of the repository in the directory
+func addUsage(usage *Usage, dir string) error {
+	usage.Repositories++
+	objectBytes, err := dirSize(path.Join(dir, repoDirName))
+	if err != nil {
+		return err
+	}
+	usage.ObjectBytes += objectBytes
+	moduleBytes, err := dirSize(path.Join(dir, modulesDir))
+	if err != nil {
+		return err
+	}
+	usage.ObjectBytes += moduleBytes
+
+	entries, err := os.ReadDir(path.Join(dir, commitsDir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		commitBytes, err := dirSize(path.Join(dir, commitsDir, e.Name()))
+		if err != nil {
+			return err
+		}
+		usage.Commits++
+		usage.CommitBytes += commitBytes
+	}
+	return nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
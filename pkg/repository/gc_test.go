@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	fixtures "github.com/go-git/go-git-fixtures/v4"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"gotest.tools/v3/assert"
+)
+
+func TestCacheGarbageCollect(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	repoURL := fixtures.Basic().One().DotGit().Root()
+
+	repo, release, err := cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	branchCommit, err := repo.FetchBranch("branch")
+	assert.NilError(t, err, "failed to fetch branch")
+	masterCommit, err := repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+	branchCommitDir, err := repo.CreateCommitDir(*branchCommit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	masterCommitDir, err := repo.CreateCommitDir(*masterCommit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	// Leftover of an interrupted checkout
+	assert.NilError(t, os.Mkdir(path.Join(path.Dir(masterCommitDir), checkoutDirPrefix+"interrupted"), 0775))
+	release()
+
+	other, release, err := cache.Open(repoURL, "default/auth", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	release()
+
+	// Directories not created by the cache are left alone
+	assert.NilError(t, os.Mkdir(path.Join(cache.dir, "lost+found"), 0775))
+
+	// The branch commit was used before the master commit
+	past := time.Now().Add(-time.Hour)
+	assert.NilError(t, os.Chtimes(branchCommitDir, past, past))
+
+	gc, err := cache.GarbageCollect([]Reference{
+		{URL: repoURL, Commit: branchCommit.String()},
+		{URL: repoURL},
+	}, 0)
+	assert.NilError(t, err, "failed to garbage collect")
+	assert.Equal(t, gc.RemovedRepositories, 1)
+	assert.Equal(t, gc.RemovedCommits, 1)
+	assert.Equal(t, gc.Usage.Repositories, 1)
+	assert.Equal(t, gc.Usage.Commits, 1)
+	assert.Assert(t, gc.Usage.ObjectBytes > 0)
+	assert.Assert(t, gc.Usage.CommitBytes > 0)
+
+	entries, err := os.ReadDir(path.Dir(masterCommitDir))
+	assert.NilError(t, err, "failed to read commits dir")
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Name(), branchCommit.String())
+	_, err = os.Stat(path.Dir(other.root))
+	assert.Assert(t, os.IsNotExist(err), "unreferenced repository should be removed")
+	_, err = os.Stat(path.Join(cache.dir, "lost+found"))
+	assert.NilError(t, err, "directories not created by the cache should be kept")
+	_, ok := cache.repos[cacheKey(repoURL, "default/auth")]
+	assert.Assert(t, !ok, "cache entry of unreferenced repository should be removed")
+
+	// Removed repositories are initialized again when opened
+	other, release, err = cache.Open(repoURL, "default/auth", nil, nil)
+	assert.NilError(t, err, "failed to reopen repository")
+	_, err = other.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+	release()
+
+	repo, release, err = cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	_, err = repo.CreateCommitDir(*masterCommit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	release()
+
+	// The most recently used commits are kept even if they aren't referenced
+	gc, err = cache.GarbageCollect([]Reference{{URL: repoURL}}, 1)
+	assert.NilError(t, err, "failed to garbage collect")
+	assert.Equal(t, gc.RemovedRepositories, 1)
+	assert.Equal(t, gc.RemovedCommits, 1)
+	assert.Equal(t, gc.Usage.Commits, 1)
+	_, err = os.Stat(masterCommitDir)
+	assert.NilError(t, err, "most recently used commit should be kept")
+}
+
+func TestCacheGarbageCollectPrunesObjects(t *testing.T) {
+	cache := NewCache(t.TempDir())
+	repoURL := fixtures.Basic().One().DotGit().Root()
+	remoteRepo, err := git.PlainOpen(repoURL)
+	assert.NilError(t, err, "failed to open repo")
+	config, err := remoteRepo.Config()
+	assert.NilError(t, err, "failed to get config")
+	config.Raw.Sections = append(config.Raw.Sections, &gitconfig.Section{
+		Name: "uploadpack",
+		Options: []*gitconfig.Option{
+			{Key: "allowReachableSHA1InWant", Value: "true"},
+		},
+	})
+	assert.NilError(t, remoteRepo.SetConfig(config), "failed to set config")
+
+	branchCommit := "e8d3ffab552895c19b9fcf7aa264d277cde33881"
+	masterCommit := "6ecf0ef2c2dffb796033e5a02219af86ec6584e5"
+	repo, release, err := cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	_, err = repo.FetchBranch("branch")
+	assert.NilError(t, err, "failed to fetch branch")
+	assert.NilError(t, repo.FetchCommit(masterCommit), "failed to fetch commit")
+	release()
+
+	gc, err := cache.GarbageCollect([]Reference{{URL: repoURL, Commit: branchCommit}}, 0)
+	assert.NilError(t, err, "failed to garbage collect")
+	assert.Assert(t, gc.PrunedObjects > 0, "objects of the unreferenced commit should be pruned")
+
+	repo, release, err = cache.Open(repoURL, "", nil, nil)
+	assert.NilError(t, err, "failed to open repository")
+	_, err = repo.repo.CommitObject(plumbing.NewHash(branchCommit))
+	assert.NilError(t, err, "referenced commit should be kept")
+	_, err = repo.repo.CommitObject(plumbing.NewHash(masterCommit))
+	assert.Equal(t, err, plumbing.ErrObjectNotFound)
+	_, err = repo.repo.Reference(fetchedCommitReferenceName(masterCommit), false)
+	assert.Equal(t, err, plumbing.ErrReferenceNotFound)
+
+	// Pruned commits are fetched again
+	assert.NilError(t, repo.FetchCommit(masterCommit), "failed to fetch pruned commit")
+	_, err = repo.CreateCommitDir(plumbing.NewHash(masterCommit), CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	release()
+
+	gc, err = cache.GarbageCollect([]Reference{{URL: repoURL, Commit: branchCommit}, {URL: repoURL, Commit: masterCommit}}, 0)
+	assert.NilError(t, err, "failed to garbage collect")
+	assert.Equal(t, gc.PrunedObjects, 0)
+}
@@ -6,6 +6,8 @@ import (
 	"path"
 	"regexp"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
@@ -15,57 +17,69 @@ import (
 )
 
 const (
-	commitsDir  = "commits"
-	repoDirName = "repo"
+	commitsDir        = "commits"
+	repoDirName       = "repo"
+	checkoutDirPrefix = ".checkout-"
 )
 
 type GitRepository struct {
 	repo git.Repository
 	auth transport.AuthMethod
+	tls  TLSConfig
 	root string
 }
 
-func InitGitRepository(dir string, url string, auth transport.AuthMethod) (*GitRepository, error) {
+// TLSConfig configures verification of the git server certificates
+type TLSConfig struct {
+	// CABundle contains PEM encoded CA certificates used in addition to the system certificates
+	CABundle []byte
+	// InsecureSkipTLS disables verification of the git server certificates
+	InsecureSkipTLS bool
+}
+
+// InitGitRepository initializes the git repository in the directory or reopens the repository initialized before.
+// Reopened repositories are recovered from partial initialization and their remote is set to the URL.
+// Repositories which can't be reopened are initialized again.
+func InitGitRepository(dir string, url string, auth transport.AuthMethod, tlsConfig *TLSConfig) (*GitRepository, error) {
 	repoDir := path.Join(dir, repoDirName)
 	r, err := git.PlainInit(repoDir, true)
+	if err == git.ErrRepositoryAlreadyExists {
+		r, err = git.PlainOpen(repoDir)
+		if err == nil {
+			_, err = r.Config()
+		}
+	}
 	if err != nil {
-		if err == git.ErrRepositoryAlreadyExists {
-			if r, err := git.PlainOpen(repoDir); err == nil {
-				return &GitRepository{
-					repo: *r,
-					auth: auth,
-					root: repoDir,
-				}, err
-			}
+		// The repository is only a cache of the remote, so it is safe to start over
+		if err := os.RemoveAll(repoDir); err != nil {
+			return nil, err
+		}
+		if r, err = git.PlainInit(repoDir, true); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 
-	_, err = r.CreateRemote(&config.RemoteConfig{
-		Name: git.DefaultRemoteName,
-		URLs: []string{url},
-	})
-	if err != nil {
+	gr := &GitRepository{
+		repo: *r,
+		root: repoDir,
+	}
+	if err := gr.setRemote(url, auth, tlsConfig); err != nil {
 		return nil, err
 	}
-
-	if err = os.Mkdir(path.Join(dir, commitsDir), 0775); err != nil {
+	if err := os.MkdirAll(path.Join(dir, commitsDir), 0775); err != nil {
 		return nil, err
 	}
-
-	return &GitRepository{
-		repo: *r,
-		auth: auth,
-		root: repoDir,
-	}, nil
+	return gr, nil
 }
 
 func (gr *GitRepository) FetchBranch(name string) (*plumbing.Hash, error) {
 	branchRefSpec := config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", name, git.DefaultRemoteName, name))
 	err := gr.repo.Fetch(&git.FetchOptions{
-		Depth:    1,
-		Auth:     gr.auth,
-		RefSpecs: []config.RefSpec{branchRefSpec},
+		Depth:           1,
+		Auth:            gr.auth,
+		RefSpecs:        []config.RefSpec{branchRefSpec},
+		CABundle:        gr.tls.CABundle,
+		InsecureSkipTLS: gr.tls.InsecureSkipTLS,
 	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
 		return nil, err
@@ -84,35 +98,179 @@ func (gr *GitRepository) FetchCommit(commit string) error {
 	if _, err := gr.repo.CommitObject(plumbing.NewHash(commit)); err == nil {
 		return nil
 	}
-	branchRefSpec := config.RefSpec(fmt.Sprintf("%s:refs/remotes/%s/%s", commit, git.DefaultRemoteName, fmt.Sprintf("commit-%s", commit)))
+	branchRefSpec := config.RefSpec(fmt.Sprintf("%s:%s", commit, fetchedCommitReferenceName(commit)))
 	return gr.repo.Fetch(&git.FetchOptions{
-		Depth:    1,
-		Auth:     gr.auth,
-		Force:    true,
-		RefSpecs: []config.RefSpec{branchRefSpec},
+		Depth:           1,
+		Auth:            gr.auth,
+		Force:           true,
+		RefSpecs:        []config.RefSpec{branchRefSpec},
+		CABundle:        gr.tls.CABundle,
+		InsecureSkipTLS: gr.tls.InsecureSkipTLS,
 	})
 }
 
-func (gr *GitRepository) CreateCommitDir(commit plumbing.Hash) (string, error) {
-	commitDir := path.Join(path.Dir(gr.root), commitsDir, commit.String())
-	repo, err := git.Open(gr.repo.Storer, osfs.New(commitDir))
+// fetchedCommitReferenceName is the name of the reference keeping the fetched commit, see pruneObjects
+func fetchedCommitReferenceName(commit string) plumbing.ReferenceName {
+	return plumbing.NewRemoteReferenceName(git.DefaultRemoteName, "commit-"+commit)
+}
+
+// CheckoutOptions configures what is checked out along with the files of the commit
+type CheckoutOptions struct {
+	// Submodules checks out the submodules of the commit, recursively
+	Submodules bool
+	// LFS replaces the Git LFS pointer files with the files they point to
+	LFS bool
+}
+
+// dirSuffix distinguishes the checkouts of the same commit with different options
+func (o CheckoutOptions) dirSuffix() string {
+	suffix := ""
+	if o.Submodules {
+		suffix += "-submodules"
+	}
+	if o.LFS {
+		suffix += "-lfs"
+	}
+	return suffix
+}
+
+// CreateCommitDir checks out the commit to its own directory and returns the path of the directory.
+// Each commit is checked out only once, the checked out files must not be modified.
+func (gr *GitRepository) CreateCommitDir(commit plumbing.Hash, options CheckoutOptions) (string, error) {
+	commitDir := path.Join(path.Dir(gr.root), commitsDir, commit.String()+options.dirSuffix())
+	if _, err := os.Stat(commitDir); err == nil {
+		// The modification time tracks when the commit was last used, see GarbageCollect
+		now := time.Now()
+		if err := os.Chtimes(commitDir, now, now); err != nil {
+			return "", err
+		}
+		return commitDir, nil
+	}
+
+	// The commit is checked out to a temporary directory which is renamed once complete,
+	// so that an interrupted checkout is never mistaken for a complete one
+	checkoutDir, err := os.MkdirTemp(path.Join(path.Dir(gr.root), commitsDir), checkoutDirPrefix)
 	if err != nil {
 		return "", err
 	}
+	defer os.RemoveAll(checkoutDir)
+
+	if err := gr.checkout(commit, checkoutDir, options, 0); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(checkoutDir, commitDir); err != nil {
+		return "", err
+	}
+	return commitDir, nil
+}
+
+// checkout checks out the commit to the directory. depth is the nesting level of the repository as a submodule.
+func (gr *GitRepository) checkout(hash plumbing.Hash, dir string, options CheckoutOptions, depth int) error {
+	repo, err := git.Open(gr.repo.Storer, osfs.New(dir))
+	if err != nil {
+		return err
+	}
 	worktree, err := repo.Worktree()
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	err = worktree.Checkout(&git.CheckoutOptions{
-		Hash:  commit,
+		Hash:  hash,
 		Force: true,
 	})
+	if err != nil {
+		return err
+	}
+
+	commit, err := gr.repo.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+	if options.Submodules {
+		if err := gr.checkoutSubmodules(commit, dir, options, depth); err != nil {
+			return err
+		}
+	}
+	if options.LFS {
+		if err := gr.pullLFS(commit, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteURL returns the URL of the remote repository
+func (gr *GitRepository) remoteURL() (string, error) {
+	remote, err := gr.repo.Remote(git.DefaultRemoteName)
 	if err != nil {
 		return "", err
 	}
+	return remote.Config().URLs[0], nil
+}
 
-	return commitDir, nil
+// credentialsFor returns the credentials and the TLS configuration of the repository if the URL is on the same host
+// and accessed with the same protocol as the repository. Otherwise the URL is accessed anonymously.
+func (gr *GitRepository) credentialsFor(url string) (transport.AuthMethod, TLSConfig, error) {
+	remoteURL, err := gr.remoteURL()
+	if err != nil {
+		return nil, TLSConfig{}, err
+	}
+	remote, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return nil, TLSConfig{}, err
+	}
+	target, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, TLSConfig{}, err
+	}
+	if remote.Host == "" || !strings.EqualFold(remote.Host, target.Host) ||
+		endpointPort(remote) != endpointPort(target) || remote.Protocol != target.Protocol {
+		return nil, TLSConfig{}, nil
+	}
+	return gr.auth, gr.tls, nil
+}
+
+var defaultPorts = map[string]int{
+	"http":  80,
+	"https": 443,
+	"ssh":   22,
+	"git":   9418,
+}
+
+func endpointPort(endpoint *transport.Endpoint) int {
+	if endpoint.Port != 0 {
+		return endpoint.Port
+	}
+	return defaultPorts[endpoint.Protocol]
+}
+
+// setRemote updates the remote URL and the credentials used to access the repository
+func (gr *GitRepository) setRemote(url string, auth transport.AuthMethod, tlsConfig *TLSConfig) error {
+	if tlsConfig == nil {
+		tlsConfig = &TLSConfig{}
+	}
+	gr.auth = auth
+	gr.tls = *tlsConfig
+
+	cfg, err := gr.repo.Config()
+	if err != nil {
+		return err
+	}
+	remote, ok := cfg.Remotes[git.DefaultRemoteName]
+	if !ok {
+		_, err := gr.repo.CreateRemote(&config.RemoteConfig{
+			Name: git.DefaultRemoteName,
+			URLs: []string{url},
+		})
+		return err
+	}
+	if len(remote.URLs) == 1 && remote.URLs[0] == url {
+		return nil
+	}
+	remote.URLs = []string{url}
+	return gr.repo.SetConfig(cfg)
 }
 
 func (gr *GitRepository) ListBranches(match string) ([]string, error) {
@@ -121,7 +279,9 @@ func (gr *GitRepository) ListBranches(match string) ([]string, error) {
 		return nil, err
 	}
 	refs, err := remote.List(&git.ListOptions{
-		Auth: gr.auth,
+		Auth:            gr.auth,
+		CABundle:        gr.tls.CABundle,
+		InsecureSkipTLS: gr.tls.InsecureSkipTLS,
 	})
 	if err != nil {
 		return nil, err
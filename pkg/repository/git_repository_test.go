@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"encoding/pem"
 	"fmt"
+	"net/http/cgi"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path"
 	"testing"
 	"time"
@@ -29,12 +33,12 @@ func TestGitRepository(t *testing.T) {
 	repoRoot := t.TempDir()
 	repoURL := fixtures.Basic().One().DotGit().Root()
 
-	repo, err := InitGitRepository(repoRoot, repoURL, nil)
+	repo, err := InitGitRepository(repoRoot, repoURL, nil, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
 	// Reinitilizing should be a no-op
-	repo, err = InitGitRepository(repoRoot, repoURL, nil)
+	repo, err = InitGitRepository(repoRoot, repoURL, nil, nil)
 	assert.NilError(t, err, "failed to get already initialized git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -48,7 +52,7 @@ func TestGitRepository(t *testing.T) {
 	assert.NilError(t, err, "failed to fetch branch")
 	assert.Equal(t, commit.String(), branchCommit, "commit sha mismatch")
 
-	commitDir, err := repo.CreateCommitDir(*commit)
+	commitDir, err := repo.CreateCommitDir(*commit, CheckoutOptions{})
 	assert.NilError(t, err, "failed to create commit dir")
 	assert.Equal(t, commitDir, path.Join(repoRoot, "commits", branchCommit))
 
@@ -61,7 +65,7 @@ func TestGitRepository(t *testing.T) {
 	assert.NilError(t, err, "failed to fetch branch")
 	assert.Equal(t, commit.String(), masterCommit, "commit sha mismatch")
 
-	commitDir, err = repo.CreateCommitDir(*commit)
+	commitDir, err = repo.CreateCommitDir(*commit, CheckoutOptions{})
 	assert.NilError(t, err, "failed to create commit dir")
 	assert.Equal(t, commitDir, path.Join(repoRoot, "commits", masterCommit))
 
@@ -70,6 +74,52 @@ func TestGitRepository(t *testing.T) {
 	assert.Equal(t, len(entries), 8)
 }
 
+func TestReopenGitRepository(t *testing.T) {
+	repoRoot := t.TempDir()
+	repoURL := fixtures.Basic().One().DotGit().Root()
+	remoteURL := func(repo *GitRepository) string {
+		remote, err := repo.repo.Remote(git.DefaultRemoteName)
+		assert.NilError(t, err, "failed to get remote")
+		return remote.Config().URLs[0]
+	}
+
+	repo, err := InitGitRepository(repoRoot, repoURL, nil, nil)
+	assert.NilError(t, err, "failed to init git repository")
+	commit, err := repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+
+	// Commits dir is missing when interrupted while initializing
+	assert.NilError(t, os.RemoveAll(path.Join(repoRoot, "commits")))
+	repo, err = InitGitRepository(repoRoot, repoURL, nil, nil)
+	assert.NilError(t, err, "failed to reopen git repository")
+	_, err = repo.CreateCommitDir(*commit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+
+	// Remote is updated when the URL changes
+	movedURL := fixtures.Basic().One().DotGit().Root()
+	repo, err = InitGitRepository(repoRoot, movedURL, nil, nil)
+	assert.NilError(t, err, "failed to reopen git repository")
+	assert.Equal(t, remoteURL(repo), movedURL)
+	_, err = repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch from changed remote")
+
+	// Remote is missing when interrupted while initializing
+	assert.NilError(t, repo.repo.DeleteRemote(git.DefaultRemoteName))
+	repo, err = InitGitRepository(repoRoot, repoURL, nil, nil)
+	assert.NilError(t, err, "failed to reopen git repository")
+	assert.Equal(t, remoteURL(repo), repoURL)
+
+	// Repository which can't be opened is initialized again
+	assert.NilError(t, os.WriteFile(path.Join(repoRoot, "repo", "config"), []byte("[remote"), 0644))
+	repo, err = InitGitRepository(repoRoot, repoURL, nil, nil)
+	assert.NilError(t, err, "failed to reinitialize git repository")
+	assert.Equal(t, remoteURL(repo), repoURL)
+	_, err = repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+	_, err = os.Stat(path.Join(repoRoot, "commits", commit.String()))
+	assert.NilError(t, err, "checked out commits should be kept")
+}
+
 func TestForcePushBranch(t *testing.T) {
 	repoRoot := t.TempDir()
 	repoURL := fixtures.Basic().One().DotGit().Root()
@@ -87,7 +137,7 @@ func TestForcePushBranch(t *testing.T) {
 	})
 	assert.NilError(t, remoteRepo.SetConfig(config), "failed to set config")
 
-	repo, err := InitGitRepository(repoRoot, repoURL, nil)
+	repo, err := InitGitRepository(repoRoot, repoURL, nil, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -131,7 +181,7 @@ func TestFetchWithTokenAuth(t *testing.T) {
 	repo, err := InitGitRepository(t.TempDir(), repoURL, &http.BasicAuth{
 		Username: "notImportant",
 		Password: githubToken(t),
-	})
+	}, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -143,7 +193,7 @@ func TestFetchWithTokenAuth(t *testing.T) {
 func TestGitRepositoryFetchCommit(t *testing.T) {
 	repoRoot := t.TempDir()
 
-	repo, err := InitGitRepository(repoRoot, "https://github.com/git-fixtures/basic.git", nil)
+	repo, err := InitGitRepository(repoRoot, "https://github.com/git-fixtures/basic.git", nil, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -155,7 +205,7 @@ func TestGitRepositoryFetchCommit(t *testing.T) {
 	err = repo.FetchCommit(commit)
 	assert.NilError(t, err, "failed to fetch commit")
 
-	commitDir, err := repo.CreateCommitDir(plumbing.NewHash(commit))
+	commitDir, err := repo.CreateCommitDir(plumbing.NewHash(commit), CheckoutOptions{})
 	assert.NilError(t, err, "failed to create commit dir")
 	assert.Equal(t, commitDir, path.Join(repoRoot, "commits", commit))
 
@@ -167,7 +217,7 @@ func TestGitRepositoryFetchCommit(t *testing.T) {
 func TestListBranches(t *testing.T) {
 	repoRoot := t.TempDir()
 
-	repo, err := InitGitRepository(repoRoot, "https://github.com/git-fixtures/basic.git", nil)
+	repo, err := InitGitRepository(repoRoot, "https://github.com/git-fixtures/basic.git", nil, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -208,7 +258,7 @@ func TestListBranchesWithAuth(t *testing.T) {
 	repo, err := InitGitRepository(t.TempDir(), repoURL, &http.BasicAuth{
 		Username: "notImportant",
 		Password: githubToken(t),
-	})
+	}, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -220,7 +270,7 @@ func TestListBranchesWithAuth(t *testing.T) {
 // Issue: https://github.com/go-git/go-git/issues/328
 // Solved by downgrading to 5.3.0 - https://github.com/go-git/go-git/issues/328#issuecomment-1086651486
 func TestGitRepositoryEmptyUploadPack(t *testing.T) {
-	repo, err := InitGitRepository(t.TempDir(), "https://github.com/git-fixtures/basic.git", nil)
+	repo, err := InitGitRepository(t.TempDir(), "https://github.com/git-fixtures/basic.git", nil, nil)
 	assert.NilError(t, err, "failed to init git repository")
 	assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -276,7 +326,7 @@ func TestForcePushThenFetch(t *testing.T) {
 			})
 			assert.NilError(t, remoteRepo.SetConfig(config), "failed to set config")
 
-			repo, err := InitGitRepository(repoRoot, repoURL, nil)
+			repo, err := InitGitRepository(repoRoot, repoURL, nil, nil)
 			assert.NilError(t, err, "failed to init git repository")
 			assert.Check(t, repo != nil, "repository should not be nil")
 
@@ -329,3 +379,66 @@ func TestForcePushThenFetch(t *testing.T) {
 		})
 	}
 }
+
+func newTLSGitServer(t *testing.T, repoDir string) (*httptest.Server, string) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git binary is required to serve repositories over HTTPS")
+	}
+
+	server := httptest.NewTLSServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env: []string{
+			fmt.Sprintf("GIT_PROJECT_ROOT=%s", path.Dir(repoDir)),
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	})
+	t.Cleanup(server.Close)
+	return server, fmt.Sprintf("%s/%s", server.URL, path.Base(repoDir))
+}
+
+func TestTLSConfig(t *testing.T) {
+	server, repoURL := newTLSGitServer(t, fixtures.Basic().One().DotGit().Root())
+	caBundle := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	})
+
+	testCases := []struct {
+		name      string
+		tlsConfig *TLSConfig
+		wantErr   bool
+	}{{
+		name:    "unknown certificate authority",
+		wantErr: true,
+	}, {
+		name:      "ca bundle",
+		tlsConfig: &TLSConfig{CABundle: caBundle},
+	}, {
+		name:      "insecure skip tls",
+		tlsConfig: &TLSConfig{InsecureSkipTLS: true},
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, err := InitGitRepository(t.TempDir(), repoURL, nil, tc.tlsConfig)
+			assert.NilError(t, err, "failed to init git repository")
+
+			branches, err := repo.ListBranches("")
+			if tc.wantErr {
+				assert.Check(t, err != nil, "expected list branches to fail")
+			} else {
+				assert.NilError(t, err, "failed to list branches")
+				assert.DeepEqual(t, branches, []string{"branch", "master"})
+			}
+
+			commit, err := repo.FetchBranch("branch")
+			if tc.wantErr {
+				assert.Check(t, err != nil, "expected fetch branch to fail")
+			} else {
+				assert.NilError(t, err, "failed to fetch branch")
+				assert.Equal(t, commit.String(), "e8d3ffab552895c19b9fcf7aa264d277cde33881")
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	gitconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+const (
+	lfsConfigFile = ".lfsconfig"
+	lfsMediaType  = "application/vnd.git-lfs+json"
+
+	// lfsPointerMaxSize is the maximum size of the pointer files, larger files are never pointers
+	lfsPointerMaxSize = 1024
+)
+
+var lfsPointerRegexp = regexp.MustCompile(`\Aversion https://git-lfs\.github\.com/spec/v1\noid sha256:([0-9a-f]{64})\nsize ([0-9]+)\n\z`)
+
+// lfsPointer is a file checked out as a pointer to the LFS object holding its content
+type lfsPointer struct {
+	path string
+	oid  string
+	size int64
+}
+
+func parseLFSPointer(name, content string) (lfsPointer, bool) {
+	match := lfsPointerRegexp.FindStringSubmatch(content)
+	if match == nil {
+		return lfsPointer{}, false
+	}
+	size, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return lfsPointer{}, false
+	}
+	return lfsPointer{path: name, oid: match[1], size: size}, true
+}
+
+type lfsBatchRequest struct {
+	Operation string           `json:"operation"`
+	Transfers []string         `json:"transfers"`
+	Objects   []lfsBatchObject `json:"objects"`
+}
+
+type lfsBatchObject struct {
+	OID     string                    `json:"oid"`
+	Size    int64                     `json:"size"`
+	Actions map[string]lfsBatchAction `json:"actions,omitempty"`
+	Error   *lfsBatchError            `json:"error,omitempty"`
+}
+
+type lfsBatchAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type lfsBatchError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lfsBatchResponse struct {
+	Objects []lfsBatchObject `json:"objects"`
+}
+
+// pullLFS replaces the LFS pointer files of the commit checked out to the directory with the files they point to
+func (gr *GitRepository) pullLFS(commit *object.Commit, dir string) error {
+	var pointers []lfsPointer
+	files, err := commit.Files()
+	if err != nil {
+		return err
+	}
+	err = files.ForEach(func(f *object.File) error {
+		if f.Size > lfsPointerMaxSize || f.Mode == filemode.Symlink {
+			return nil
+		}
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		if pointer, ok := parseLFSPointer(f.Name, content); ok {
+			pointers = append(pointers, pointer)
+		}
+		return nil
+	})
+	if err != nil || len(pointers) == 0 {
+		return err
+	}
+
+	endpoint, err := gr.lfsEndpoint(commit)
+	if err != nil {
+		return err
+	}
+	auth, tlsConfig, err := gr.credentialsFor(endpoint)
+	if err != nil {
+		return err
+	}
+	client, err := lfsHTTPClient(tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	objects, err := lfsBatch(client, auth, endpoint, pointers)
+	if err != nil {
+		return fmt.Errorf("failed to request LFS objects from %s: %v", endpoint, err)
+	}
+	for _, pointer := range pointers {
+		object, ok := objects[pointer.oid]
+		if !ok {
+			return fmt.Errorf("LFS object %s of %s is missing in the response", pointer.oid, pointer.path)
+		}
+		if object.Error != nil {
+			return fmt.Errorf("failed to get LFS object %s of %s: %s", pointer.oid, pointer.path, object.Error.Message)
+		}
+		download, ok := object.Actions["download"]
+		if !ok {
+			return fmt.Errorf("LFS object %s of %s can't be downloaded", pointer.oid, pointer.path)
+		}
+		if err := gr.downloadLFSObject(client, download, pointer, path.Join(dir, pointer.path)); err != nil {
+			return fmt.Errorf("failed to download LFS object %s of %s: %v", pointer.oid, pointer.path, err)
+		}
+	}
+	return nil
+}
+
+// lfsEndpoint returns the URL of the LFS server, set in the .lfsconfig of the commit or derived from the repository URL
+func (gr *GitRepository) lfsEndpoint(commit *object.Commit) (string, error) {
+	if file, err := commit.File(lfsConfigFile); err == nil {
+		content, err := file.Contents()
+		if err != nil {
+			return "", err
+		}
+		cfg := gitconfig.New()
+		if err := gitconfig.NewDecoder(strings.NewReader(content)).Decode(cfg); err != nil {
+			return "", fmt.Errorf("invalid %s: %v", lfsConfigFile, err)
+		}
+		if url := cfg.Section("lfs").Option("url"); url != "" {
+			return strings.TrimSuffix(url, "/"), nil
+		}
+	} else if err != object.ErrFileNotFound {
+		return "", err
+	}
+
+	remoteURL, err := gr.remoteURL()
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return "", err
+	}
+	repoPath := strings.TrimSuffix(endpoint.Path, "/")
+	if !strings.HasSuffix(repoPath, ".git") {
+		repoPath += ".git"
+	}
+	switch endpoint.Protocol {
+	case "http", "https":
+		endpoint.Path = repoPath + "/info/lfs"
+		endpoint.User = ""
+		endpoint.Password = ""
+		return endpoint.String(), nil
+	case "ssh":
+		return fmt.Sprintf("https://%s/%s/info/lfs", endpoint.Host, strings.TrimPrefix(repoPath, "/")), nil
+	default:
+		return "", fmt.Errorf("LFS server of %s is unknown, it needs to be set in %s", remoteURL, lfsConfigFile)
+	}
+}
+
+func lfsHTTPClient(tlsConfig TLSConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: tlsConfig.InsecureSkipTLS,
+	}
+	if len(tlsConfig.CABundle) > 0 {
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(tlsConfig.CABundle) {
+			return nil, fmt.Errorf("failed to parse CA bundle")
+		}
+		transport.TLSClientConfig.RootCAs = rootCAs
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   10 * time.Minute,
+	}, nil
+}
+
+func setLFSAuth(req *http.Request, auth transport.AuthMethod) {
+	switch auth := auth.(type) {
+	case *githttp.BasicAuth:
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case *githttp.TokenAuth:
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	}
+}
+
+// lfsBatch requests the download actions of the LFS objects, keyed by the object ID
+func lfsBatch(client *http.Client, auth transport.AuthMethod, endpoint string, pointers []lfsPointer) (map[string]lfsBatchObject, error) {
+	batch := lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+	}
+	for _, pointer := range pointers {
+		batch.Objects = append(batch.Objects, lfsBatchObject{OID: pointer.oid, Size: pointer.size})
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	setLFSAuth(req, auth)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	response := lfsBatchResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	objects := map[string]lfsBatchObject{}
+	for _, object := range response.Objects {
+		objects[object.OID] = object
+	}
+	return objects, nil
+}
+
+// downloadLFSObject downloads the LFS object to the file, verifying its content
+func (gr *GitRepository) downloadLFSObject(client *http.Client, action lfsBatchAction, pointer lfsPointer, file string) error {
+	req, err := http.NewRequest(http.MethodGet, action.Href, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range action.Header {
+		req.Header.Set(key, value)
+	}
+	if req.Header.Get("Authorization") == "" {
+		auth, _, err := gr.credentialsFor(action.Href)
+		if err != nil {
+			return err
+		}
+		setLFSAuth(req, auth)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(resp.Body, pointer.size+1))
+	if err != nil {
+		return err
+	}
+	if size != pointer.size || hex.EncodeToString(hash.Sum(nil)) != pointer.oid {
+		return fmt.Errorf("downloaded content doesn't match the object")
+	}
+	return f.Close()
+}
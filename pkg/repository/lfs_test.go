@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"gotest.tools/v3/assert"
+)
+
+func lfsPointerFile(content string) (string, string) {
+	sum := sha256.Sum256([]byte(content))
+	oid := hex.EncodeToString(sum[:])
+	return oid, fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+}
+
+// newAuthGitServer serves the repositories in the root directory and the LFS objects over HTTPS,
+// requiring basic auth for all requests
+func newAuthGitServer(t *testing.T, root string, auth *githttp.BasicAuth, objects map[string]string) *httptest.Server {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git binary is required to serve repositories over HTTPS")
+	}
+	gitHandler := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env: []string{
+			fmt.Sprintf("GIT_PROJECT_ROOT=%s", root),
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	}
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != auth.Username || password != auth.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/info/lfs/objects/batch"):
+			batch := lfsBatchRequest{}
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil || batch.Operation != "download" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			response := lfsBatchResponse{}
+			for _, object := range batch.Objects {
+				object.Actions = map[string]lfsBatchAction{
+					"download": {Href: fmt.Sprintf("%s/lfs/objects/%s", server.URL, object.OID)},
+				}
+				response.Objects = append(response.Objects, object)
+			}
+			w.Header().Set("Content-Type", lfsMediaType)
+			json.NewEncoder(w).Encode(response)
+		case strings.HasPrefix(r.URL.Path, "/lfs/objects/"):
+			content, ok := objects[path.Base(r.URL.Path)]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(content))
+		default:
+			gitHandler.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckoutLFS(t *testing.T) {
+	crds := strings.Repeat("kind: CustomResourceDefinition\n", 100)
+	crdsOID, crdsPointer := lfsPointerFile(crds)
+	libCRDs := "kind: CustomResourceDefinition\n"
+	libCRDsOID, libCRDsPointer := lfsPointerFile(libCRDs)
+	brokenOID, brokenPointer := lfsPointerFile("broken")
+
+	root := t.TempDir()
+	newBareRepo(t, root, "lib", map[string]string{"crds.yaml": libCRDsPointer}, nil)
+	commit := newBareRepo(t, root, "parent", map[string]string{
+		"crds.yaml":  crdsPointer,
+		"small.yaml": "kind: ConfigMap\n",
+	}, map[string]string{"lib": "../lib.git"})
+	brokenCommit := newBareRepo(t, root, "broken", map[string]string{"crds.yaml": brokenPointer}, nil)
+
+	auth := &githttp.BasicAuth{Username: "kuberik", Password: "secret"}
+	server := newAuthGitServer(t, root, auth, map[string]string{
+		crdsOID:    crds,
+		libCRDsOID: libCRDs,
+		brokenOID:  "tampered",
+	})
+	tlsConfig := &TLSConfig{InsecureSkipTLS: true}
+
+	repo, err := InitGitRepository(t.TempDir(), server.URL+"/parent.git", auth, tlsConfig)
+	assert.NilError(t, err, "failed to init git repository")
+	_, err = repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+
+	commitDir, err := repo.CreateCommitDir(commit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	data, err := os.ReadFile(path.Join(commitDir, "crds.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), crdsPointer, "LFS files should be pulled only when enabled")
+
+	// The credentials of the repository are reused for the submodules and the LFS server on the same host
+	commitDir, err = repo.CreateCommitDir(commit, CheckoutOptions{Submodules: true, LFS: true})
+	assert.NilError(t, err, "failed to create commit dir")
+	assert.Equal(t, path.Base(commitDir), commit.String()+"-submodules-lfs")
+	for file, content := range map[string]string{
+		"crds.yaml":     crds,
+		"small.yaml":    "kind: ConfigMap\n",
+		"lib/crds.yaml": libCRDs,
+	} {
+		data, err := os.ReadFile(path.Join(commitDir, file))
+		assert.NilError(t, err, "failed to read %s", file)
+		assert.Equal(t, string(data), content)
+	}
+
+	broken, err := InitGitRepository(t.TempDir(), server.URL+"/broken.git", auth, tlsConfig)
+	assert.NilError(t, err, "failed to init git repository")
+	_, err = broken.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+	_, err = broken.CreateCommitDir(brokenCommit, CheckoutOptions{LFS: true})
+	assert.ErrorContains(t, err, "downloaded content doesn't match the object")
+	entries, err := os.ReadDir(path.Join(path.Dir(broken.root), commitsDir))
+	assert.NilError(t, err, "failed to read commits dir")
+	assert.Equal(t, len(entries), 0, "failed checkouts should be cleaned up")
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	oid, pointer := lfsPointerFile("content")
+	parsed, ok := parseLFSPointer("file.yaml", pointer)
+	assert.Assert(t, ok, "pointer should be parsed")
+	assert.Equal(t, parsed, lfsPointer{path: "file.yaml", oid: oid, size: 7})
+
+	for _, content := range []string{
+		"",
+		"content",
+		strings.TrimSuffix(pointer, "\n"),
+		strings.Replace(pointer, "sha256:", "sha1:", 1),
+		pointer + "ext-0-foo sha256:" + oid + "\n",
+	} {
+		_, ok := parseLFSPointer("file.yaml", content)
+		assert.Assert(t, !ok, "%q should not be parsed as pointer", content)
+	}
+}
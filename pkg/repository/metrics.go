@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	cacheRepositories = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kuberik_repository_cache_repositories",
+		Help: "Number of git repositories in the repository cache",
+	})
+	cacheCommits = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kuberik_repository_cache_commits",
+		Help: "Number of checked out commits in the repository cache",
+	})
+	cacheDiskUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kuberik_repository_cache_disk_usage_bytes",
+		Help: "Disk space used by the repository cache, by the git objects and by the checked out commits",
+	}, []string{"type"})
+	cacheRemoved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kuberik_repository_cache_gc_removed_total",
+		Help: "Total number of repositories, checked out commits and pruned git objects removed from the repository cache",
+	}, []string{"type"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cacheRepositories, cacheCommits, cacheDiskUsage, cacheRemoved)
+}
+
+func recordGarbageCollection(gc GarbageCollection) {
+	cacheRepositories.Set(float64(gc.Usage.Repositories))
+	cacheCommits.Set(float64(gc.Usage.Commits))
+	cacheDiskUsage.WithLabelValues("objects").Set(float64(gc.Usage.ObjectBytes))
+	cacheDiskUsage.WithLabelValues("commits").Set(float64(gc.Usage.CommitBytes))
+	cacheRemoved.WithLabelValues("repository").Add(float64(gc.RemovedRepositories))
+	cacheRemoved.WithLabelValues("commit").Add(float64(gc.RemovedCommits))
+	cacheRemoved.WithLabelValues("object").Add(float64(gc.PrunedObjects))
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+const (
+	gitmodulesFile = ".gitmodules"
+	modulesDir     = "modules"
+
+	// maxSubmoduleDepth limits the nesting of the submodules, e.g. when submodules reference each other
+	maxSubmoduleDepth = 10
+)
+
+// checkoutSubmodules checks out the submodules of the commit checked out to the directory.
+// The repositories of the submodules are kept in the directory of the repository, so they are fetched only once.
+func (gr *GitRepository) checkoutSubmodules(commit *object.Commit, dir string, options CheckoutOptions, depth int) error {
+	file, err := commit.File(gitmodulesFile)
+	if err == object.ErrFileNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if depth >= maxSubmoduleDepth {
+		return fmt.Errorf("submodules are nested deeper than %d levels", maxSubmoduleDepth)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return err
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(content)); err != nil {
+		return fmt.Errorf("invalid %s: %v", gitmodulesFile, err)
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	remoteURL, err := gr.remoteURL()
+	if err != nil {
+		return err
+	}
+	for _, module := range modules.Submodules {
+		if err := module.Validate(); err != nil {
+			return fmt.Errorf("invalid submodule %s: %v", module.Name, err)
+		}
+		entry, err := tree.FindEntry(module.Path)
+		if err != nil || entry.Mode != filemode.Submodule {
+			return fmt.Errorf("submodule %s is not committed at %s", module.Name, module.Path)
+		}
+
+		url, err := resolveSubmoduleURL(remoteURL, module.URL)
+		if err != nil {
+			return fmt.Errorf("invalid URL of submodule %s: %v", module.Name, err)
+		}
+		auth, tlsConfig, err := gr.credentialsFor(url)
+		if err != nil {
+			return err
+		}
+		submodule, err := InitGitRepository(path.Join(path.Dir(gr.root), modulesDir, cacheKey(url, "")), url, auth, &tlsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to init submodule %s: %v", module.Name, err)
+		}
+		if err := submodule.fetchSubmoduleCommit(entry.Hash); err != nil {
+			return fmt.Errorf("failed to fetch submodule %s: %v", module.Name, err)
+		}
+		if err := submodule.checkout(entry.Hash, path.Join(dir, module.Path), options, depth+1); err != nil {
+			return fmt.Errorf("failed to check out submodule %s: %v", module.Name, err)
+		}
+	}
+	return nil
+}
+
+// fetchSubmoduleCommit fetches the commit, falling back to fetching all the branches and tags
+// when the server doesn't allow fetching the commit directly
+func (gr *GitRepository) fetchSubmoduleCommit(commit plumbing.Hash) error {
+	if err := gr.FetchCommit(commit.String()); err == nil {
+		return nil
+	}
+	err := gr.repo.Fetch(&git.FetchOptions{
+		Auth:  gr.auth,
+		Force: true,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", git.DefaultRemoteName)),
+			"+refs/tags/*:refs/tags/*",
+		},
+		CABundle:        gr.tls.CABundle,
+		InsecureSkipTLS: gr.tls.InsecureSkipTLS,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return err
+	}
+	if _, err := gr.repo.CommitObject(commit); err != nil {
+		return fmt.Errorf("commit %s not found: %v", commit, err)
+	}
+	return nil
+}
+
+// resolveSubmoduleURL resolves the URL of the submodule relative to the URL of the repository, like git does
+// for the URLs starting with ./ or ../
+// Submodules on the local file system are refused unless the repository is local too, since .gitmodules
+// could otherwise be used to read other repositories on the disk of the controller.
+func resolveSubmoduleURL(repoURL, submoduleURL string) (string, error) {
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(submoduleURL, "./") || strings.HasPrefix(submoduleURL, "../") {
+		endpoint.Path = path.Join(endpoint.Path, submoduleURL)
+		if endpoint.Protocol == "file" {
+			return endpoint.Path, nil
+		}
+		return endpoint.String(), nil
+	}
+
+	submodule, err := transport.NewEndpoint(submoduleURL)
+	if err != nil {
+		return "", err
+	}
+	if submodule.Protocol == "file" && endpoint.Protocol != "file" {
+		return "", fmt.Errorf("local submodules are only allowed in local repositories")
+	}
+	return submoduleURL, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"gotest.tools/v3/assert"
+)
+
+// runGit runs the git binary in the directory and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "protocol.file.allow=always"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=kuberik", "GIT_AUTHOR_EMAIL=kuberik@example.com",
+		"GIT_COMMITTER_NAME=kuberik", "GIT_COMMITTER_EMAIL=kuberik@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+	)
+	out, err := cmd.CombinedOutput()
+	assert.NilError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	return strings.TrimSpace(string(out))
+}
+
+// newBareRepo commits the files and the submodules to a new bare repository named name.git in the root directory
+// and returns the commit. Submodules map the paths to the URLs of the submodules.
+func newBareRepo(t *testing.T, root, name string, files map[string]string, submodules map[string]string) plumbing.Hash {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary is required to create repositories with submodules")
+	}
+
+	work := path.Join(root, name+"-work")
+	assert.NilError(t, os.Mkdir(work, 0775))
+	runGit(t, work, "init", "--initial-branch=master")
+	for file, content := range files {
+		assert.NilError(t, os.MkdirAll(path.Dir(path.Join(work, file)), 0775))
+		assert.NilError(t, os.WriteFile(path.Join(work, file), []byte(content), 0664))
+	}
+	for modulePath, url := range submodules {
+		runGit(t, work, "submodule", "add", url, modulePath)
+	}
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-m", "init")
+	runGit(t, root, "clone", "--bare", work, name+".git")
+	return plumbing.NewHash(runGit(t, work, "rev-parse", "HEAD"))
+}
+
+func TestCheckoutSubmodules(t *testing.T) {
+	root := t.TempDir()
+	newBareRepo(t, root, "nested", map[string]string{"nested.yaml": "nested"}, nil)
+	newBareRepo(t, root, "lib", map[string]string{"lib.yaml": "lib"}, map[string]string{"nested": "../nested.git"})
+	commit := newBareRepo(t, root, "parent", map[string]string{"kustomization.yaml": "resources: [lib]"}, map[string]string{
+		"lib":      "../lib.git",
+		"absolute": path.Join(root, "nested.git"),
+	})
+
+	repo, err := InitGitRepository(t.TempDir(), path.Join(root, "parent.git"), nil, nil)
+	assert.NilError(t, err, "failed to init git repository")
+	_, err = repo.FetchBranch("master")
+	assert.NilError(t, err, "failed to fetch branch")
+
+	commitDir, err := repo.CreateCommitDir(commit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+	entries, err := os.ReadDir(path.Join(commitDir, "lib"))
+	assert.NilError(t, err, "failed to read submodule dir")
+	assert.Equal(t, len(entries), 0, "submodules should be checked out only when enabled")
+
+	commitDir, err = repo.CreateCommitDir(commit, CheckoutOptions{Submodules: true})
+	assert.NilError(t, err, "failed to create commit dir")
+	assert.Equal(t, path.Base(commitDir), commit.String()+"-submodules")
+	for file, content := range map[string]string{
+		"kustomization.yaml":     "resources: [lib]",
+		"lib/lib.yaml":           "lib",
+		"lib/nested/nested.yaml": "nested",
+		"absolute/nested.yaml":   "nested",
+	} {
+		data, err := os.ReadFile(path.Join(commitDir, file))
+		assert.NilError(t, err, "failed to read %s", file)
+		assert.Equal(t, string(data), content)
+	}
+
+	// The submodule repositories are kept next to the repository, the nested submodules share the same repository
+	entries, err = os.ReadDir(path.Join(path.Dir(repo.root), modulesDir))
+	assert.NilError(t, err, "failed to read modules dir")
+	assert.Equal(t, len(entries), 2)
+}
+
+func TestCheckoutLocalSubmodulesRefused(t *testing.T) {
+	root := t.TempDir()
+	newBareRepo(t, root, "private", map[string]string{"secret.yaml": "secret"}, nil)
+	for name, url := range map[string]string{
+		"path":     path.Join(root, "private.git"),
+		"file-url": "file://" + path.Join(root, "private.git"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			commit := newBareRepo(t, root, name, nil, map[string]string{"private": url})
+			_, repoURL := newTLSGitServer(t, path.Join(root, name+".git"))
+			repo, err := InitGitRepository(t.TempDir(), repoURL, nil, &TLSConfig{InsecureSkipTLS: true})
+			assert.NilError(t, err, "failed to init git repository")
+			_, err = repo.FetchBranch("master")
+			assert.NilError(t, err, "failed to fetch branch")
+
+			_, err = repo.CreateCommitDir(commit, CheckoutOptions{Submodules: true})
+			assert.ErrorContains(t, err, "local submodules are only allowed in local repositories")
+			_, err = os.Stat(path.Join(path.Dir(repo.root), modulesDir))
+			assert.Assert(t, os.IsNotExist(err), "local submodule should not be fetched")
+		})
+	}
+}
+
+func TestResolveSubmoduleURL(t *testing.T) {
+	for _, tc := range []struct {
+		repoURL      string
+		submoduleURL string
+		want         string
+	}{
+		{"https://example.com/org/repo.git", "../lib.git", "https://example.com/org/lib.git"},
+		{"https://example.com/org/repo.git", "./lib", "https://example.com/org/repo.git/lib"},
+		{"https://example.com/org/repo", "../../other/lib.git", "https://example.com/other/lib.git"},
+		{"https://example.com/org/repo.git", "https://example.org/lib.git", "https://example.org/lib.git"},
+		{"ssh://git@example.com/org/repo.git", "../lib.git", "ssh://git@example.com/org/lib.git"},
+		{"git@example.com:org/repo.git", "../lib.git", "ssh://git@example.com/org/lib.git"},
+		{"/srv/git/repo.git", "../lib.git", "/srv/git/lib.git"},
+		{"/srv/git/repo.git", "/srv/git/lib.git", "/srv/git/lib.git"},
+		{"file:///srv/git/repo.git", "file:///srv/git/lib.git", "file:///srv/git/lib.git"},
+	} {
+		t.Run(tc.repoURL+" "+tc.submoduleURL, func(t *testing.T) {
+			url, err := resolveSubmoduleURL(tc.repoURL, tc.submoduleURL)
+			assert.NilError(t, err)
+			assert.Equal(t, url, tc.want)
+		})
+	}
+
+	for _, submoduleURL := range []string{"/srv/git/lib.git", "file:///srv/git/lib.git", "lib.git"} {
+		_, err := resolveSubmoduleURL("https://example.com/org/repo.git", submoduleURL)
+		assert.ErrorContains(t, err, "local submodules are only allowed in local repositories", submoduleURL)
+	}
+}
+
+func TestCredentialsFor(t *testing.T) {
+	auth := &http.BasicAuth{Username: "kuberik", Password: "secret"}
+	tlsConfig := &TLSConfig{InsecureSkipTLS: true}
+	repo, err := InitGitRepository(t.TempDir(), "https://example.com/org/repo.git", auth, tlsConfig)
+	assert.NilError(t, err, "failed to init git repository")
+
+	for _, tc := range []struct {
+		url    string
+		reused bool
+	}{
+		{url: "https://example.com/org/lib.git", reused: true},
+		{url: "https://EXAMPLE.com:443/other/lib.git", reused: true},
+		{url: "https://example.com:8443/org/lib.git"},
+		{url: "http://example.com/org/lib.git"},
+		{url: "http://example.com:443/org/lib.git"},
+		{url: "https://example.org/org/lib.git"},
+		{url: "ssh://git@example.com/org/lib.git"},
+		{url: "git@example.com:org/lib.git"},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			gotAuth, gotTLS, err := repo.credentialsFor(tc.url)
+			assert.NilError(t, err)
+			if tc.reused {
+				assert.Equal(t, gotAuth, auth)
+				assert.DeepEqual(t, gotTLS, *tlsConfig)
+			} else {
+				assert.Assert(t, gotAuth == nil, "credentials should not be reused")
+				assert.DeepEqual(t, gotTLS, TLSConfig{})
+			}
+		})
+	}
+}
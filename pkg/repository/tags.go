@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// ListTags lists the tags of the remote repository
+func (gr *GitRepository) ListTags() ([]string, error) {
+	remote, err := gr.repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := remote.List(&git.ListOptions{
+		Auth:            gr.auth,
+		CABundle:        gr.tls.CABundle,
+		InsecureSkipTLS: gr.tls.InsecureSkipTLS,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+	return tags, nil
+}
+
+// FetchTag fetches the tag and returns the commit it points to
+func (gr *GitRepository) FetchTag(name string) (*plumbing.Hash, error) {
+	tagRefName := plumbing.NewTagReferenceName(name)
+	tagRefSpec := config.RefSpec(fmt.Sprintf("+%s:%s", tagRefName, tagRefName))
+	err := gr.repo.Fetch(&git.FetchOptions{
+		Depth:           1,
+		Auth:            gr.auth,
+		RefSpecs:        []config.RefSpec{tagRefSpec},
+		Tags:            git.NoTags,
+		CABundle:        gr.tls.CABundle,
+		InsecureSkipTLS: gr.tls.InsecureSkipTLS,
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, err
+	}
+
+	tagReference, err := gr.repo.Reference(tagRefName, true)
+	if err != nil {
+		return nil, err
+	}
+	hash := tagReference.Hash()
+	// Annotated tags point to the tag object instead of the commit
+	if tag, err := gr.repo.TagObject(hash); err == nil {
+		commit, err := tag.Commit()
+		if err != nil {
+			return nil, fmt.Errorf("tag %s doesn't point to a commit: %v", name, err)
+		}
+		hash = commit.Hash
+	}
+	return &hash, nil
+}
+
+// SelectSemverTag returns the tag of the highest semantic version matching the constraint.
+// Tags which aren't semantic versions, optionally prefixed with v, are ignored.
+func SelectSemverTag(tags []string, constraint string) (string, error) {
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("invalid semver constraint %q: %v", constraint, err)
+	}
+
+	var selected string
+	var selectedVersion *semver.Version
+	for _, tag := range tags {
+		// Versions are parsed strictly, so that tags like 1.2 aren't taken for versions
+		version, err := semver.StrictNewVersion(strings.TrimPrefix(tag, "v"))
+		if err != nil || !constraints.Check(version) {
+			continue
+		}
+		if selectedVersion == nil || version.GreaterThan(selectedVersion) {
+			selected = tag
+			selectedVersion = version
+		}
+	}
+	if selectedVersion == nil {
+		return "", fmt.Errorf("no tag matches semver constraint %q", constraint)
+	}
+	return selected, nil
+}
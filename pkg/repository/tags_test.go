@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"testing"
+
+	fixtures "github.com/go-git/go-git-fixtures/v4"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestSelectSemverTag(t *testing.T) {
+	tags := []string{"latest", "v1.0.0", "v1.2.0", "1.3.1", "v1.10.0-rc.1", "v2.0.0", "v2.1.0-beta.1", "1.4", "v1.5", "vv1.6.0", "01.7.0"}
+	for _, tc := range []struct {
+		constraint string
+		tag        string
+		err        string
+	}{
+		{constraint: ">=1.0.0", tag: "v2.0.0"},
+		{constraint: ">=1.2.0 <2.0.0", tag: "1.3.1"},
+		{constraint: "~1.2", tag: "v1.2.0"},
+		{constraint: "^1.0.0", tag: "1.3.1"},
+		{constraint: ">=1.2.0-0 <2.0.0-0", tag: "v1.10.0-rc.1"},
+		{constraint: ">=2.0.0-0", tag: "v2.1.0-beta.1"},
+		{constraint: ">=3.0.0", err: `no tag matches semver constraint ">=3.0.0"`},
+		{constraint: "latest", err: `invalid semver constraint "latest"`},
+	} {
+		t.Run(tc.constraint, func(t *testing.T) {
+			tag, err := SelectSemverTag(tags, tc.constraint)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tag, tc.tag)
+		})
+	}
+}
+
+func TestFetchTag(t *testing.T) {
+	repoURL := fixtures.Basic().One().DotGit().Root()
+	remoteRepo, err := git.PlainOpen(repoURL)
+	assert.NilError(t, err, "failed to open repo")
+
+	lightweightCommit := plumbing.NewHash("918c48b83bd081e863dbe1b80f8998f058cd8294")
+	_, err = remoteRepo.CreateTag("v2.0.0", lightweightCommit, nil)
+	assert.NilError(t, err, "failed to create tag")
+	annotatedCommit := plumbing.NewHash("6ecf0ef2c2dffb796033e5a02219af86ec6584e5")
+	_, err = remoteRepo.CreateTag("v2.1.0", annotatedCommit, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "kuberik", Email: "kuberik@example.com"},
+		Message: "v2.1.0",
+	})
+	assert.NilError(t, err, "failed to create tag")
+
+	repo, err := InitGitRepository(t.TempDir(), repoURL, nil, nil)
+	assert.NilError(t, err, "failed to init git repository")
+
+	tags, err := repo.ListTags()
+	assert.NilError(t, err, "failed to list tags")
+	assert.Check(t, cmp.Contains(tags, "v1.0.0"))
+	assert.Check(t, cmp.Contains(tags, "v2.0.0"))
+	assert.Check(t, cmp.Contains(tags, "v2.1.0"))
+
+	commit, err := repo.FetchTag("v2.0.0")
+	assert.NilError(t, err, "failed to fetch tag")
+	assert.Equal(t, *commit, lightweightCommit)
+
+	commit, err = repo.FetchTag("v2.1.0")
+	assert.NilError(t, err, "failed to fetch tag")
+	assert.Equal(t, *commit, annotatedCommit)
+
+	_, err = repo.CreateCommitDir(*commit, CheckoutOptions{})
+	assert.NilError(t, err, "failed to create commit dir")
+
+	// Moved tags are fetched again
+	assert.NilError(t, remoteRepo.DeleteTag("v2.0.0"))
+	_, err = remoteRepo.CreateTag("v2.0.0", annotatedCommit, nil)
+	assert.NilError(t, err, "failed to move tag")
+	commit, err = repo.FetchTag("v2.0.0")
+	assert.NilError(t, err, "failed to fetch tag")
+	assert.Equal(t, *commit, annotatedCommit)
+
+	_, err = repo.FetchTag("v9.9.9")
+	assert.Assert(t, err != nil, "fetching missing tag should fail")
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// NormalizeURL returns the location of the repository independent of the protocol used
+// to access it, so that e.g. HTTPS and SSH URLs of the same repository are equal.
+// It is meant for matching the repositories of webhooks, the repositories are fetched from remoteLocation.
+func NormalizeURL(url string) string {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return url
+	}
+
+	repoPath := strings.Trim(endpoint.Path, "/")
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	repoPath = strings.TrimSuffix(repoPath, "/")
+	if endpoint.Host == "" {
+		return "/" + repoPath
+	}
+	return strings.ToLower(endpoint.Host) + "/" + repoPath
+}
+
+// remoteLocation returns the location of the repository including the protocol, user and port used to access it,
+// so that only the URLs fetching the repository from the same server are equal
+func remoteLocation(url string) string {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return url
+	}
+
+	repoPath := strings.TrimSuffix(strings.TrimSuffix(endpoint.Path, "/"), ".git")
+	if endpoint.Protocol == "file" {
+		return "file://" + repoPath
+	}
+	return fmt.Sprintf("%s://%s@%s:%d/%s", endpoint.Protocol, endpoint.User, strings.ToLower(endpoint.Host), endpointPort(endpoint), strings.TrimPrefix(repoPath, "/"))
+}
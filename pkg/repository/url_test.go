@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	for _, url := range []string{
+		"https://github.com/kuberik/kuberik.git",
+		"https://github.com/kuberik/kuberik",
+		"https://GitHub.com/kuberik/kuberik/",
+		"https://user@github.com/kuberik/kuberik.git",
+		"http://github.com:80/kuberik/kuberik.git",
+		"git@github.com:kuberik/kuberik.git",
+		"ssh://git@github.com/kuberik/kuberik.git",
+		"ssh://git@github.com:22/kuberik/kuberik",
+	} {
+		assert.Equal(t, NormalizeURL(url), "github.com/kuberik/kuberik", url)
+	}
+
+	assert.Equal(t, NormalizeURL("file:///tmp/repo/.git"), "/tmp/repo")
+	assert.Equal(t, NormalizeURL("/tmp/repo/.git"), "/tmp/repo")
+	assert.Assert(t, NormalizeURL("https://github.com/kuberik/kuberik") != NormalizeURL("https://gitlab.com/kuberik/kuberik"))
+}
+
+func TestRemoteLocation(t *testing.T) {
+	for url, location := range map[string]string{
+		"https://github.com/kuberik/kuberik.git":     "https://@github.com:443/kuberik/kuberik",
+		"https://GitHub.com:443/kuberik/kuberik/":    "https://@github.com:443/kuberik/kuberik",
+		"https://github.com:8443/kuberik/kuberik":    "https://@github.com:8443/kuberik/kuberik",
+		"http://github.com/kuberik/kuberik":          "http://@github.com:80/kuberik/kuberik",
+		"git@github.com:kuberik/kuberik.git":         "ssh://git@github.com:22/kuberik/kuberik",
+		"ssh://git@github.com:2222/kuberik/kuberik/": "ssh://git@github.com:2222/kuberik/kuberik",
+		"/tmp/repo.git": "file:///tmp/repo",
+	} {
+		assert.Equal(t, remoteLocation(url), location, url)
+	}
+}